@@ -34,56 +34,75 @@ Using the above example again, the three entries that sum to 2020 are 979, 366,
 
 In your expense report, what is the product of the three entries that sum to 2020?
 */
-package main
+package day01
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 	"strconv"
-	"strings"
 
 	"github.com/FollowTheProcess/advent_of_code_2020/hashset"
-	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
 )
 
-func main() {
-	if err := run(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
+// TARGET is what the entries in the expense report need to sum to
+const TARGET = 2020
+
+// ErrNoMatch is returned when no entries in the report sum to the target
+var ErrNoMatch = errors.New("no entries sum to the target")
+
+func init() {
+	solution.Register(2020, 1, func() solution.Solution { return &puzzle{} })
 }
 
-func run() error {
-	inputFile := filepath.Join(utils.Root(), "day01", "day01.txt")
-	input, err := os.ReadFile(inputFile)
-	if err != nil {
-		return err
-	}
+type puzzle struct {
+	entries []int
+}
 
-	inStr := string(input)
-	entries := strings.Split(inStr, "\n")
+func (p *puzzle) Parse(_ context.Context, r io.Reader) error {
+	entries, err := ParseReport(r)
+	p.entries = entries
+	return err
+}
 
-	intEntries := []int{}
-	for _, i := range entries {
-		entry, _ := strconv.Atoi(i)
-		intEntries = append(intEntries, entry)
-	}
+func (p *puzzle) Part1(_ context.Context) (solution.Answer, error) {
+	product, err := PairProduct(p.entries, TARGET)
+	return solution.Int(product), err
+}
 
-	fmt.Printf("Part 1: %d\n\n", part1(intEntries))
-	fmt.Printf("Part 2: %d\n", part2(intEntries))
-	return nil
+func (p *puzzle) Part2(_ context.Context) (solution.Answer, error) {
+	product, err := TripleProduct(p.entries, TARGET)
+	return solution.Int(product), err
 }
 
-func part1(entries []int) int {
+// ParseReport parses an expense report, one entry per line
+func ParseReport(r io.Reader) ([]int, error) {
+	var entries []int
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanWords)
+	for scanner.Scan() {
+		entry, err := strconv.Atoi(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("bad entry in expense report: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// PairProduct finds the two entries that sum to target and returns their product
+func PairProduct(entries []int, target int) (int, error) {
 	original := hashset.IntHashSet{}
 	for _, entry := range entries {
 		original.Add(entry)
 	}
-	// What needs to be added to the original to make 2020
+	// What needs to be added to the original to make target
 	needs := hashset.IntHashSet{}
 	for _, entry := range entries {
-		needs.Add(2020 - entry)
+		needs.Add(target - entry)
 	}
 
 	// See if any of these diffs are in the original set
@@ -94,26 +113,22 @@ func part1(entries []int) int {
 		}
 	}
 
-	// Return the product of the things that add up to 2020
+	// Return the product of the things that add up to target
 	// according to the problem there should only be two
-	if len(matches) > 2 {
-		panic("Matches should only contain 2 elements")
+	if len(matches) != 2 {
+		return 0, fmt.Errorf("%w: %d matching entries, expected 2", ErrNoMatch, len(matches))
 	}
 
-	return matches[0] * matches[1]
+	return matches[0] * matches[1], nil
 }
 
-func part2(entries []int) int {
-	original := hashset.IntHashSet{}
-	for _, entry := range entries {
-		original.Add(entry)
-	}
-
+// TripleProduct finds the three entries that sum to target and returns their product
+func TripleProduct(entries []int, target int) (int, error) {
 	needs := make(map[int][]int)
 	for _, i := range entries {
 		for _, j := range entries {
 			if i != j {
-				needs[2020-i-j] = []int{i, j}
+				needs[target-i-j] = []int{i, j}
 			}
 		}
 	}
@@ -121,9 +136,9 @@ func part2(entries []int) int {
 	for _, entry := range entries {
 		if n, ok := needs[entry]; ok {
 			j, k := n[0], n[1]
-			return entry * j * k
+			return entry * j * k, nil
 		}
 	}
 
-	return 0
+	return 0, ErrNoMatch
 }
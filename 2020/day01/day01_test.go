@@ -0,0 +1,53 @@
+package day01
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestParseReport(t *testing.T) {
+	is := is.New(t)
+
+	entries, err := ParseReport(strings.NewReader("1721\n979\n366\n"))
+	is.NoErr(err)
+	is.Equal(entries, []int{1721, 979, 366})
+
+	_, err = ParseReport(strings.NewReader("1721\nnope\n"))
+	is.True(err != nil)
+}
+
+func TestExamplePart1(t *testing.T) {
+	is := is.New(t)
+	input := []int{1721, 979, 366, 299, 675, 1456}
+
+	want := 514579
+
+	got, err := PairProduct(input, TARGET)
+	is.NoErr(err)
+	is.Equal(got, want)
+}
+
+func TestExamplePart2(t *testing.T) {
+	is := is.New(t)
+	input := []int{1721, 979, 366, 299, 675, 1456}
+
+	want := 241861950
+
+	got, err := TripleProduct(input, TARGET)
+	is.NoErr(err)
+	is.Equal(got, want)
+}
+
+func TestNoMatch(t *testing.T) {
+	is := is.New(t)
+	input := []int{1, 2, 3}
+
+	_, err := PairProduct(input, TARGET)
+	is.True(errors.Is(err, ErrNoMatch))
+
+	_, err = TripleProduct(input, TARGET)
+	is.True(errors.Is(err, ErrNoMatch))
+}
@@ -34,25 +34,30 @@ Given the same example list from above:
 1-3 b: cdefg is invalid: neither position 1 nor position 3 contains b.
 2-9 c: ccccccccc is invalid: both position 2 and position 9 contain c.
 How many passwords are valid according to the new interpretation of the policies?
-
 */
-package main
+package day02
 
 import (
+	"bufio"
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 	"strconv"
 	"strings"
 
-	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
 )
 
+func init() {
+	solution.Register(2020, 2, func() solution.Solution { return &puzzle{} })
+}
+
+// Password is a password from the database along with the policy it was set under
 type Password struct {
-	Text   string
-	Letter string
-	Min    int
-	Max    int
+	Text   string // The password itself
+	Letter string // The letter the policy is about
+	Min    int    // The first number in the policy
+	Max    int    // The second number in the policy
 }
 
 // Parse takes the raw line e.g. '1-3 a: abcde' and returns a Password
@@ -107,6 +112,11 @@ func (p *Password) IsValidPart2() bool {
 	// Compensate for no zero index, 1 actually means 0 etc
 	minIndex, maxIndex := p.Min-1, p.Max-1
 
+	// A position off the end can't contain anything
+	if minIndex < 0 || maxIndex >= len(p.Text) {
+		return false
+	}
+
 	// Only 1 of these positions can contain letter
 	// If both, then false
 	if string(p.Text[minIndex]) == p.Letter && string(p.Text[maxIndex]) == p.Letter {
@@ -122,48 +132,50 @@ func (p *Password) IsValidPart2() bool {
 	return true
 }
 
-func main() {
-	if err := run(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-}
-
-func run() error {
-	inputFile := filepath.Join(utils.Root(), "day01", "day01.txt")
-	raw, err := os.ReadFile(inputFile)
-	if err != nil {
-		return err
-	}
-
-	lines := strings.FieldsFunc(string(raw), func(r rune) bool { return r == '\n' })
-
+// ParseAll parses every line of the password database
+func ParseAll(r io.Reader) ([]*Password, error) {
 	var passwords []*Password
-	for _, line := range lines {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
 		p, err := Parse(line)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		passwords = append(passwords, p)
 	}
+	return passwords, scanner.Err()
+}
 
-	// Count the valid ones
-	valid := 0
+// Count returns how many of passwords are valid according to valid
+// e.g. Count(passwords, (*Password).IsValid)
+func Count(passwords []*Password, valid func(p *Password) bool) int {
+	n := 0
 	for _, password := range passwords {
-		if password.IsValid() {
-			valid++
+		if valid(password) {
+			n++
 		}
 	}
+	return n
+}
 
-	validPart2 := 0
-	for _, password := range passwords {
-		if password.IsValidPart2() {
-			validPart2++
-		}
-	}
+type puzzle struct {
+	passwords []*Password
+}
 
-	fmt.Printf("Part 1: %d\n\n", valid)
-	fmt.Printf("Part 2: %d\n", validPart2)
+func (p *puzzle) Parse(_ context.Context, r io.Reader) error {
+	passwords, err := ParseAll(r)
+	p.passwords = passwords
+	return err
+}
+
+func (p *puzzle) Part1(_ context.Context) (solution.Answer, error) {
+	return solution.Int(Count(p.passwords, (*Password).IsValid)), nil
+}
 
-	return nil
+func (p *puzzle) Part2(_ context.Context) (solution.Answer, error) {
+	return solution.Int(Count(p.passwords, (*Password).IsValidPart2)), nil
 }
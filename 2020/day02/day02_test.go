@@ -1,6 +1,7 @@
-package main
+package day02
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
@@ -82,3 +83,17 @@ func TestIsValidPart2(t *testing.T) {
 		})
 	}
 }
+
+func TestCount(t *testing.T) {
+	is := is.New(t)
+
+	passwords, err := ParseAll(strings.NewReader("1-3 a: abcde\n1-3 b: cdefg\n2-9 c: ccccccccc\n"))
+	is.NoErr(err)
+	is.Equal(len(passwords), 3)
+
+	is.Equal(Count(passwords, (*Password).IsValid), 2)
+	is.Equal(Count(passwords, (*Password).IsValidPart2), 1)
+
+	_, err = ParseAll(strings.NewReader("1-3 a: abcde\nnonsense\n"))
+	is.True(err != nil)
+}
@@ -66,91 +66,72 @@ Right 1, down 2.
 In the above example, these slopes would find 2, 7, 3, 4, and 2 tree(s) respectively; multiplied together, these produce the answer 336.
 
 What do you get if you multiply together the number of trees encountered on each of the listed slopes?
-
 */
-package main
+package day03
 
 import (
-	"bytes"
-	"fmt"
-	"os"
-	"path/filepath"
+	"bufio"
+	"context"
+	"io"
 
-	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
 )
 
-func main() {
-	if err := run(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-}
+// TREE marks a tree on the map, everything else is open ground
+const TREE = '#'
 
-func run() error {
-	inputFile := filepath.Join(utils.Root(), "day03", "day03.txt")
-	data, err := os.ReadFile(inputFile)
-	if err != nil {
-		return err
-	}
+func init() {
+	solution.Register(2020, 3, func() solution.Solution { return &puzzle{} })
+}
 
-	p1 := part1(data)
-	p2 := part2(data)
+type puzzle struct {
+	grid Grid
+}
 
-	fmt.Printf("Part 1: %d\n\n", p1)
-	fmt.Printf("Part 2: %d\n", p2)
-	return nil
+func (p *puzzle) Parse(_ context.Context, r io.Reader) error {
+	grid, err := ParseGrid(r)
+	p.grid = grid
+	return err
 }
 
-func part1(data []byte) int {
-	var matrix [][]rune
-	for _, line := range bytes.Split(data, []byte("\n")) {
-		if len(line) == 0 {
-			continue
-		}
-		row := []rune{}
-		for _, char := range string(line) {
-			row = append(row, char)
-		}
-		matrix = append(matrix, row)
-	}
+func (p *puzzle) Part1(_ context.Context) (solution.Answer, error) {
+	return solution.Int(part1(p.grid)), nil
+}
 
-	return toboggan(matrix, 3, 1)
+func (p *puzzle) Part2(_ context.Context) (solution.Answer, error) {
+	return solution.Int(part2(p.grid)), nil
 }
 
-func part2(data []byte) int {
-	var matrix [][]rune
-	for _, line := range bytes.Split(data, []byte("\n")) {
-		if len(line) == 0 {
+// Grid is the map of the slope, each row repeats forever to the right
+type Grid [][]rune
+
+// ParseGrid parses the map, one row per line
+func ParseGrid(r io.Reader) (Grid, error) {
+	var grid Grid
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
 			continue
 		}
-		row := []rune{}
-		for _, char := range string(line) {
-			row = append(row, char)
-		}
-		matrix = append(matrix, row)
+		grid = append(grid, []rune(line))
 	}
-
-	// Could paralellise this but honestly it's not worth it
-	r1d1 := toboggan(matrix, 1, 1)
-	r3d1 := toboggan(matrix, 3, 1)
-	r5d1 := toboggan(matrix, 5, 1)
-	r7d1 := toboggan(matrix, 7, 1)
-	r1d2 := toboggan(matrix, 1, 2)
-
-	return r1d1 * r3d1 * r5d1 * r7d1 * r1d2
+	return grid, scanner.Err()
 }
 
-func toboggan(matrix [][]rune, rightStep, downStep int) int {
+// Toboggan counts the trees hit going from the top left to the bottom of
+// the grid, moving rightStep across and downStep down each time
+func (g Grid) Toboggan(rightStep, downStep int) int {
 	down := downStep
 	right := rightStep
 
 	trees := 0
 
-	for down < len(matrix) {
-		row := matrix[down]
+	for down < len(g) {
+		row := g[down]
 		// Clever trick with the modulo operator telling us where we are in the next
 		// repeating pattern
-		if row[right%len(row)] == '#' {
+		if row[right%len(row)] == TREE {
 			trees++
 		}
 		down += downStep
@@ -159,3 +140,18 @@ func toboggan(matrix [][]rune, rightStep, downStep int) int {
 
 	return trees
 }
+
+func part1(grid Grid) int {
+	return grid.Toboggan(3, 1)
+}
+
+func part2(grid Grid) int {
+	// Could paralellise this but honestly it's not worth it
+	r1d1 := grid.Toboggan(1, 1)
+	r3d1 := grid.Toboggan(3, 1)
+	r5d1 := grid.Toboggan(5, 1)
+	r7d1 := grid.Toboggan(7, 1)
+	r1d2 := grid.Toboggan(1, 2)
+
+	return r1d1 * r3d1 * r5d1 * r7d1 * r1d2
+}
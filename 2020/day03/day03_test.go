@@ -0,0 +1,40 @@
+package day03
+
+import (
+	"os"
+	"testing"
+)
+
+// example returns the grid from the puzzle description
+func example(t *testing.T) Grid {
+	t.Helper()
+	f, err := os.Open("testdata/example1.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	grid, err := ParseGrid(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grid
+}
+
+func TestExamplePart1(t *testing.T) {
+	answer := part1(example(t))
+	want := 7
+
+	if answer != want {
+		t.Errorf("got %d, wanted %d", answer, want)
+	}
+}
+
+func TestExamplePart2(t *testing.T) {
+	answer := part2(example(t))
+	want := 336
+
+	if answer != want {
+		t.Errorf("got %d, wanted %d", answer, want)
+	}
+}
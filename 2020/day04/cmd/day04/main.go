@@ -0,0 +1,11 @@
+// Command day04 prints the answers to 2020 day 4 for the puzzle input
+package main
+
+import (
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day04"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+func main() {
+	solution.Main(2020, 4)
+}
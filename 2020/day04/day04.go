@@ -115,29 +115,37 @@ Count the number of valid passports - those that have all required fields and va
 
 */
 
-package main
+package day04
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 	"regexp"
 	"strconv"
 	"strings"
 
-	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
 )
 
+func init() {
+	solution.Register(2020, 4, func() solution.Solution { return &puzzle{} })
+}
+
+// hairColorRegex matches a valid hair colour, # followed by 6 characters 0-9 or a-f
+var hairColorRegex = regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
+
+// Passport is one batch of passport data, missing fields are the zero value
 type Passport struct {
-	BirthYear      int
-	IssueYear      int
-	ExpirationYear int
-	Height         int
-	HeightUnit     string
-	HairColor      string
-	EyeColor       string
-	PID            string
-	CID            string
+	BirthYear      int    // byr
+	IssueYear      int    // iyr
+	ExpirationYear int    // eyr
+	Height         int    // The number part of hgt
+	HeightUnit     string // "cm" or "in" from hgt, if it had one
+	HairColor      string // hcl
+	EyeColor       string // ecl
+	PID            string // pid, the passport ID
+	CID            string // cid, the country ID
 }
 
 // IsValid determines if the caller is a valid passport
@@ -213,9 +221,7 @@ func (p *Passport) IsValid2() bool {
 	}
 
 	// Hair color needs to be a valid hex code
-	// # followed by 6 characters 0-9 or a-f
-	re := regexp.MustCompile(`^#(?:[0-9a-fA-F]{3}){1,2}$`)
-	if !re.MatchString(p.HairColor) {
+	if !hairColorRegex.MatchString(p.HairColor) {
 		return false
 	}
 
@@ -330,47 +336,50 @@ func Parse(text string) (*Passport, error) {
 	return p, nil
 }
 
-func main() {
-	if err := run(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
-}
-
-func run() error {
-	input, err := os.ReadFile(filepath.Join(utils.Root(), "day04", "day04.txt"))
+// ParseAll parses the batch file, passports are separated by blank lines
+func ParseAll(r io.Reader) ([]*Passport, error) {
+	input, err := io.ReadAll(r)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	items := strings.Split(string(input), "\n\n")
-
 	var passports []*Passport
-	for _, item := range items {
+	for _, item := range strings.Split(string(input), "\n\n") {
 		p, err := Parse(item)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		passports = append(passports, p)
 	}
+	return passports, nil
+}
 
-	// Count up the valid ones
-	valid := 0
+// Count returns how many of passports are valid according to valid
+// e.g. Count(passports, (*Passport).IsValid)
+func Count(passports []*Passport, valid func(p *Passport) bool) int {
+	n := 0
 	for _, passport := range passports {
-		if passport.IsValid() {
-			valid++
+		if valid(passport) {
+			n++
 		}
 	}
+	return n
+}
 
-	// Part 2
-	validPart2 := 0
-	for _, passport := range passports {
-		if passport.IsValid2() {
-			validPart2++
-		}
-	}
+type puzzle struct {
+	passports []*Passport
+}
+
+func (p *puzzle) Parse(_ context.Context, r io.Reader) error {
+	passports, err := ParseAll(r)
+	p.passports = passports
+	return err
+}
+
+func (p *puzzle) Part1(_ context.Context) (solution.Answer, error) {
+	return solution.Int(Count(p.passports, (*Passport).IsValid)), nil
+}
 
-	fmt.Printf("Part 1: %d\n", valid)
-	fmt.Printf("Part 2: %d\n", validPart2)
-	return nil
+func (p *puzzle) Part2(_ context.Context) (solution.Answer, error) {
+	return solution.Int(Count(p.passports, (*Passport).IsValid2)), nil
 }
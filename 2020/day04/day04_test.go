@@ -1,6 +1,7 @@
-package main
+package day04
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/matryer/is"
@@ -243,3 +244,16 @@ func TestPassport_IsValid2(t *testing.T) {
 		})
 	}
 }
+
+func TestCount(t *testing.T) {
+	is := is.New(t)
+	batch := "ecl:gry pid:860033327 eyr:2020 hcl:#fffffd\nbyr:1937 iyr:2017 cid:147 hgt:183cm\n\n" +
+		"iyr:2013 ecl:amb cid:350 eyr:2023 pid:028048884\nhcl:#cfa07d byr:1929\n\n" +
+		"hcl:#ae17e1 iyr:2013\neyr:2024\necl:brn pid:760753108 byr:1931\nhgt:179cm\n\n" +
+		"hcl:#cfa07d eyr:2025 pid:166559648\niyr:2011 ecl:brn hgt:59in\n"
+
+	passports, err := ParseAll(strings.NewReader(batch))
+	is.NoErr(err)
+	is.Equal(len(passports), 4)
+	is.Equal(Count(passports, (*Passport).IsValid), 2)
+}
@@ -0,0 +1,11 @@
+// Command day05 prints the answers to 2020 day 5 for the puzzle input
+package main
+
+import (
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day05"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+func main() {
+	solution.Main(2020, 5)
+}
@@ -49,18 +49,18 @@ It's a completely full flight, so your seat should be the only missing boarding
 Your seat wasn't at the very front or back, though; the seats with IDs +1 and -1 from yours will be in your list.
 
 What is the ID of your seat?
-
 */
-package main
+package day05
 
 import (
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
-	"os"
-	"path/filepath"
+	"io"
 	"sort"
-	"strings"
 
-	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
 )
 
 const (
@@ -68,58 +68,81 @@ const (
 	maxCol = 7
 )
 
-func main() {
-	if err := run(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
-	}
+func init() {
+	solution.Register(2020, 5, func() solution.Solution { return &puzzle{} })
 }
 
-func run() error {
-	data, err := os.ReadFile(filepath.Join(utils.Root(), "day05", "day05.txt"))
-	if err != nil {
-		return err
-	}
-
-	max := 0
-	seats := []int{}
+type puzzle struct {
+	seats []int // Seat IDs in order
+}
 
-	for _, line := range strings.Split(string(data), "\n") {
-		if len(line) == 0 {
+func (p *puzzle) Parse(_ context.Context, r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
 			continue
 		}
 
-		row, col, err := findSeat(line)
+		row, col, err := FindSeat(line)
 		if err != nil {
 			return err
 		}
 
-		seatID := row*8 + col
-		seats = append(seats, seatID)
+		p.seats = append(p.seats, SeatID(row, col))
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if len(p.seats) == 0 {
+		return errors.New("no seats in input")
+	}
 
-		if seatID > max {
-			max = seatID
-		}
+	sort.Ints(p.seats)
+	return nil
+}
+
+func (p *puzzle) Part1(_ context.Context) (solution.Answer, error) {
+	return solution.Int(p.seats[len(p.seats)-1]), nil
+}
+
+func (p *puzzle) Part2(_ context.Context) (solution.Answer, error) {
+	seat, ok := MissingSeat(p.seats)
+	if !ok {
+		return solution.Answer{}, errors.New("no gap in the seats")
 	}
+	return solution.Int(seat), nil
+}
 
-	fmt.Printf("Part 1: %d\n", max)
+// SeatID returns the unique ID of the seat at row and col
+func SeatID(row, col int) int {
+	return row*8 + col
+}
 
-	// Find the missing seat by sorting and finding the gap
-	sort.Ints(seats)
+// MissingSeat finds the only seat ID missing from the middle of sorted seat IDs,
+// ok is false if there's no gap
+func MissingSeat(seats []int) (seat int, ok bool) {
+	if len(seats) == 0 {
+		return 0, false
+	}
+	// Find the missing seat by finding the gap in the sorted IDs
 	prev := seats[0]
-	for i := 1; i < len(seats); i++ {
-		if seats[i] != prev+1 {
+	for _, seat := range seats[1:] {
+		if seat != prev+1 {
 			// We've found the gap!
-			fmt.Printf("Part 2: %d\n", prev+1)
-			break
+			return prev + 1, true
 		}
-		prev = seats[i]
+		prev = seat
 	}
-
-	return nil
+	return 0, false
 }
 
-func findSeat(code string) (row, col int, err error) {
+// FindSeat decodes a boarding pass e.g. FBFBBFFRLR into the row and column of its seat
+func FindSeat(code string) (row, col int, err error) {
+	if len(code) != 10 {
+		return 0, 0, fmt.Errorf("boarding pass should be 10 characters, got %q", code)
+	}
+
 	// First, find the row
 	start := 0
 	end := maxRow
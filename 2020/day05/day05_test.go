@@ -1,4 +1,4 @@
-package main
+package day05
 
 import (
 	"testing"
@@ -6,7 +6,7 @@ import (
 	"github.com/matryer/is"
 )
 
-func Test_findSeat(t *testing.T) {
+func TestFindSeat(t *testing.T) {
 	type args struct {
 		code string
 	}
@@ -38,12 +38,22 @@ func Test_findSeat(t *testing.T) {
 			wantCol: 4,
 			wantErr: false,
 		},
+		{
+			name:    "too short",
+			args:    args{code: "BFFFBBF"},
+			wantErr: true,
+		},
+		{
+			name:    "bad letter",
+			args:    args{code: "BFFFXBFRRR"},
+			wantErr: true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			is := is.New(t)
 
-			gotRow, gotCol, err := findSeat(tt.args.code)
+			gotRow, gotCol, err := FindSeat(tt.args.code)
 
 			is.True((err != nil) == tt.wantErr)
 			is.Equal(gotRow, tt.wantRow)
@@ -51,3 +61,17 @@ func Test_findSeat(t *testing.T) {
 		})
 	}
 }
+
+func TestMissingSeat(t *testing.T) {
+	is := is.New(t)
+
+	seat, ok := MissingSeat([]int{SeatID(70, 6), SeatID(70, 7), SeatID(71, 1)})
+	is.True(ok)
+	is.Equal(seat, SeatID(71, 0))
+
+	_, ok = MissingSeat([]int{5, 6, 7})
+	is.True(!ok)
+
+	_, ok = MissingSeat(nil)
+	is.True(!ok)
+}
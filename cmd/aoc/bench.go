@@ -0,0 +1,274 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// RUNS is how many times bench runs each day by default
+const RUNS = 20
+
+// The phases of a day that are timed separately
+const (
+	phaseParse = "parse"
+	phasePart1 = "part 1"
+	phasePart2 = "part 2"
+)
+
+// sample is one timed run of a phase
+type sample struct {
+	elapsed time.Duration
+	allocs  uint64
+	bytes   uint64
+}
+
+// measure runs fn once, recording how long it took and what it allocated
+func measure(fn func() error) (sample, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	err := fn()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return sample{
+		elapsed: elapsed,
+		allocs:  after.Mallocs - before.Mallocs,
+		bytes:   after.TotalAlloc - before.TotalAlloc,
+	}, err
+}
+
+// phaseStats summarises every run of one phase of a day
+type phaseStats struct {
+	Phase       string        `json:"phase"`
+	Year        int           `json:"year"`
+	Day         int           `json:"day"`
+	Runs        int           `json:"runs"`
+	Median      time.Duration `json:"median_ns"`
+	P95         time.Duration `json:"p95_ns"`
+	AllocsPerOp uint64        `json:"allocs_per_op"`
+	BytesPerOp  uint64        `json:"bytes_per_op"`
+}
+
+// summarise works out the stats for a phase from its samples, there must be at least one
+func summarise(phase string, year, day int, samples []sample) phaseStats {
+	times := make([]time.Duration, 0, len(samples))
+	var allocs, bytes uint64
+	for _, s := range samples {
+		times = append(times, s.elapsed)
+		allocs += s.allocs
+		bytes += s.bytes
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+
+	n := len(samples)
+	median := times[n/2]
+	if n%2 == 0 {
+		median = (times[n/2-1] + times[n/2]) / 2
+	}
+	// Nearest rank, so with fewer than 20 runs it's the slowest
+	p95 := times[(n*95+99)/100-1]
+
+	return phaseStats{
+		Phase:       phase,
+		Year:        year,
+		Day:         day,
+		Runs:        n,
+		Median:      median,
+		P95:         p95,
+		AllocsPerOp: allocs / uint64(n),
+		BytesPerOp:  bytes / uint64(n),
+	}
+}
+
+// benchDay runs a day runs times on input and returns the stats for parsing
+// and each part, an extra untimed run first warms things up
+func benchDay(ctx context.Context, puzzle solution.Puzzle, input []byte, runs int) ([]phaseStats, error) {
+	samples := make(map[string][]sample, 3)
+	for i := 0; i <= runs; i++ {
+		s := puzzle.New()
+		phases := []struct {
+			name string
+			fn   func() error
+		}{
+			{name: phaseParse, fn: func() error { return s.Parse(ctx, bytes.NewReader(input)) }},
+			{name: phasePart1, fn: func() error { _, err := s.Part1(ctx); return err }},
+			{name: phasePart2, fn: func() error { _, err := s.Part2(ctx); return err }},
+		}
+		for _, phase := range phases {
+			got, err := measure(phase.fn)
+			if err != nil {
+				return nil, fmt.Errorf("day %d %s: %w", puzzle.Day, phase.name, err)
+			}
+			if i > 0 {
+				samples[phase.name] = append(samples[phase.name], got)
+			}
+		}
+	}
+
+	stats := make([]phaseStats, 0, 3)
+	for _, phase := range []string{phaseParse, phasePart1, phasePart2} {
+		stats = append(stats, summarise(phase, puzzle.Year, puzzle.Day, samples[phase]))
+	}
+	return stats, nil
+}
+
+// formatDuration rounds d to 3 significant figures or so, nobody cares about
+// the nanoseconds of something that takes milliseconds
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	case d >= time.Microsecond:
+		return d.Round(10 * time.Nanosecond).String()
+	default:
+		return d.String()
+	}
+}
+
+// renderBenchTable writes stats as a table aligned for the terminal
+func renderBenchTable(w io.Writer, stats []phaseStats) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "Day\tPhase\tRuns\tMedian\tp95\tAllocs/op\tB/op\t")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%s\t%s\t%d\t%d\t\n", s.Day, s.Phase, s.Runs, formatDuration(s.Median), formatDuration(s.P95), s.AllocsPerOp, s.BytesPerOp)
+	}
+	return tw.Flush()
+}
+
+// renderBenchMarkdown writes stats as a Markdown table, for pasting into a PR
+func renderBenchMarkdown(w io.Writer, stats []phaseStats) error {
+	var b strings.Builder
+	b.WriteString("| Day | Phase | Runs | Median | p95 | Allocs/op | B/op |\n")
+	b.WriteString("|----:|:------|-----:|-------:|----:|----------:|-----:|\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "| %d | %s | %d | %s | %s | %d | %d |\n", s.Day, s.Phase, s.Runs, formatDuration(s.Median), formatDuration(s.P95), s.AllocsPerOp, s.BytesPerOp)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// renderBenchJSON writes stats as a JSON array, durations are in nanoseconds
+func renderBenchJSON(w io.Writer, stats []phaseStats) error {
+	if stats == nil {
+		stats = []phaseStats{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(stats)
+}
+
+// benchRenderers are the output formats bench supports
+var benchRenderers = map[string]func(w io.Writer, stats []phaseStats) error{
+	"table":    renderBenchTable,
+	"json":     renderBenchJSON,
+	"markdown": renderBenchMarkdown,
+}
+
+// runBench implements the 'bench' command
+func runBench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	year := yearFlag(flags)
+	runs := flags.Int("runs", RUNS, "How many times to run each day")
+	format := flags.String("format", "table", "Output format: table, json or markdown")
+	record := flags.Bool("record", false, "Record each day's part medians as its runtimes in the README")
+	var src solution.Source
+	src.RegisterFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := resolveYear(year); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) > 1 {
+		return fmt.Errorf("bench expects at most one arg 'day' or 'all', got: %v", args)
+	}
+	which := "all"
+	if len(args) == 1 {
+		which = args[0]
+	}
+	if *runs < 1 {
+		return fmt.Errorf("runs should be at least 1, got: %d", *runs)
+	}
+	render, ok := benchRenderers[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, should be table, json or markdown", *format)
+	}
+	if err := src.Validate(); err != nil {
+		return err
+	}
+	if *record && (src.Path != "" || src.Example != 0) {
+		return errors.New("--record only makes sense for the puzzle input, not --input or --example")
+	}
+
+	days, err := selectDays(*year, which, src)
+	if err != nil {
+		return err
+	}
+
+	var stats []phaseStats
+	failed := 0
+	for _, day := range days {
+		dayStats, err := benchSource(context.Background(), *year, day, src, *runs)
+		if err != nil {
+			if len(days) == 1 {
+				return err
+			}
+			// The numbers for the rest are still worth having
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+			continue
+		}
+		stats = append(stats, dayStats...)
+		if *record {
+			if err := saveTimings(*year, day, timingsFrom(dayStats)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := render(os.Stdout, stats); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(days))
+	}
+	return nil
+}
+
+// benchSource benchmarks year's day on the input from src
+func benchSource(ctx context.Context, year, day int, src solution.Source, runs int) ([]phaseStats, error) {
+	puzzle, err := solution.Get(year, day)
+	if err != nil {
+		return nil, err
+	}
+	f, err := src.Open(year, day)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Read it once up front so the disk isn't part of the parse time
+	input, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	return benchDay(ctx, puzzle, input, runs)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/matryer/is"
+)
+
+// words is a solution that keeps every word of its input
+type words struct {
+	words []string
+}
+
+func (w *words) Parse(_ context.Context, r io.Reader) error {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	w.words = strings.Fields(string(input))
+	return nil
+}
+
+func (w *words) Part1(_ context.Context) (solution.Answer, error) {
+	return solution.Int(len(w.words)), nil
+}
+
+func (w *words) Part2(_ context.Context) (solution.Answer, error) {
+	if len(w.words) == 0 {
+		return solution.Answer{}, errors.New("no words")
+	}
+	return solution.Text(strings.Join(w.words, ",")), nil
+}
+
+func TestSummarise(t *testing.T) {
+	is := is.New(t)
+
+	var samples []sample
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, sample{elapsed: time.Duration(21-i) * time.Millisecond, allocs: 3, bytes: 100})
+	}
+	got := summarise(phasePart1, 2020, 3, samples)
+	is.Equal(got.Runs, 20)
+	is.Equal(got.Median, 10500*time.Microsecond) // Halfway between 10 and 11
+	is.Equal(got.P95, 19*time.Millisecond)
+	is.Equal(got.AllocsPerOp, uint64(3))
+	is.Equal(got.BytesPerOp, uint64(100))
+
+	one := summarise(phaseParse, 2020, 3, samples[:1])
+	is.Equal(one.Median, 20*time.Millisecond)
+	is.Equal(one.P95, 20*time.Millisecond)
+}
+
+func TestBenchDay(t *testing.T) {
+	is := is.New(t)
+	puzzle := solution.Puzzle{Year: 1999, Day: 6, New: func() solution.Solution { return &words{} }}
+
+	stats, err := benchDay(context.Background(), puzzle, []byte("a b c\n"), 5)
+	is.NoErr(err)
+	is.Equal(len(stats), 3)
+	for i, phase := range []string{phaseParse, phasePart1, phasePart2} {
+		is.Equal(stats[i].Phase, phase)
+		is.Equal(stats[i].Runs, 5)
+		is.Equal(stats[i].Day, 6)
+	}
+	is.True(stats[0].AllocsPerOp > 0) // Parsing copies the input
+	is.Equal(timingsFrom(stats), timings{Runs: 5, Part1: stats[1].Median, Part2: stats[2].Median})
+
+	_, err = benchDay(context.Background(), puzzle, nil, 5)
+	is.True(err != nil)
+	is.Equal(err.Error(), "day 6 part 2: no words")
+}
+
+func TestRenderBench(t *testing.T) {
+	is := is.New(t)
+	stats := []phaseStats{
+		{Year: 2020, Day: 1, Phase: phaseParse, Runs: 10, Median: 12345 * time.Nanosecond, P95: 2345678 * time.Nanosecond, AllocsPerOp: 4, BytesPerOp: 2048},
+		{Year: 2020, Day: 1, Phase: phasePart1, Runs: 10, Median: 800, P95: 950, AllocsPerOp: 0, BytesPerOp: 0},
+	}
+
+	buf := &bytes.Buffer{}
+	is.NoErr(renderBenchTable(buf, stats))
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	is.Equal(len(lines), 3)
+	is.True(strings.Contains(lines[1], "12.35µs"))
+	is.True(strings.Contains(lines[1], "2.346ms"))
+	is.Equal(len(lines[0]), len(lines[2])) // Aligned
+
+	buf.Reset()
+	is.NoErr(renderBenchMarkdown(buf, stats))
+	is.True(strings.HasPrefix(buf.String(), "| Day | Phase |"))
+	is.True(strings.Contains(buf.String(), "| 1 | part 1 | 10 | 800ns | 950ns | 0 | 0 |\n"))
+
+	buf.Reset()
+	is.NoErr(renderBenchJSON(buf, stats))
+	var decoded []map[string]interface{}
+	is.NoErr(json.Unmarshal(buf.Bytes(), &decoded))
+	is.Equal(decoded[0]["median_ns"], float64(12345))
+	is.Equal(decoded[1]["phase"], "part 1")
+
+	buf.Reset()
+	is.NoErr(renderBenchJSON(buf, nil))
+	is.Equal(buf.String(), "[]\n")
+}
@@ -0,0 +1,12 @@
+// Code generated by 'go run ./scripts new'. DO NOT EDIT.
+
+package main
+
+// Every day's solution, imported so it registers itself with the runner
+import (
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day01"
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day02"
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day03"
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day04"
+	_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day05"
+)
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// resultJSON is the schema of a result in the json and ndjson formats, it
+// mustn't change as scripts and dashboards depend on it
+type resultJSON struct {
+	Year     int    `json:"year"`
+	Day      int    `json:"day"`
+	Part     int    `json:"part"`
+	Answer   string `json:"answer"`   // Always a string, some answers aren't numbers
+	Duration int64  `json:"duration"` // Nanoseconds
+	Error    string `json:"error"`    // "" if the part succeeded
+}
+
+// toJSON converts a result to its stable schema
+func toJSON(r solution.Result) resultJSON {
+	out := resultJSON{
+		Year:     r.Year,
+		Day:      r.Day,
+		Part:     r.Part,
+		Answer:   r.Answer.String(),
+		Duration: r.Duration.Nanoseconds(),
+	}
+	if r.Err != nil {
+		out.Answer = ""
+		out.Error = r.Err.Error()
+	}
+	return out
+}
+
+// resultWriter writes the results of a run in some format, one day at a time in day order
+type resultWriter interface {
+	// Write writes one day's results, formats that can't stream may hold on to them
+	Write(results []solution.Result) error
+
+	// Flush writes anything held back, it's called once at the end
+	Flush() error
+}
+
+// textWriter writes each answer on its own line as it comes in, errors are
+// left to the caller as they go to stderr
+type textWriter struct {
+	w io.Writer
+}
+
+func (t textWriter) Write(results []solution.Result) error {
+	for _, r := range results {
+		if r.Err != nil {
+			continue
+		}
+		if _, err := fmt.Fprintf(t.w, "Day %d part %d: %s\n", r.Day, r.Part, r.Answer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t textWriter) Flush() error { return nil }
+
+// ndjsonWriter writes one JSON object per line per result as it comes in
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+func (n ndjsonWriter) Write(results []solution.Result) error {
+	for _, r := range results {
+		if err := n.enc.Encode(toJSON(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (n ndjsonWriter) Flush() error { return nil }
+
+// jsonWriter writes every result as one JSON array at the end
+type jsonWriter struct {
+	w       io.Writer
+	results []resultJSON
+}
+
+func (j *jsonWriter) Write(results []solution.Result) error {
+	for _, r := range results {
+		j.results = append(j.results, toJSON(r))
+	}
+	return nil
+}
+
+func (j *jsonWriter) Flush() error {
+	if j.results == nil {
+		j.results = []resultJSON{}
+	}
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.results)
+}
+
+// markdownWriter writes every result as a Markdown table at the end
+type markdownWriter struct {
+	w       io.Writer
+	results []solution.Result
+}
+
+func (m *markdownWriter) Write(results []solution.Result) error {
+	m.results = append(m.results, results...)
+	return nil
+}
+
+func (m *markdownWriter) Flush() error {
+	var b strings.Builder
+	b.WriteString("| Year | Day | Part | Answer | Duration | Error |\n")
+	b.WriteString("|-----:|----:|-----:|:-------|---------:|:------|\n")
+	for _, r := range m.results {
+		answer, errText := r.Answer.String(), ""
+		if r.Err != nil {
+			answer, errText = "", r.Err.Error()
+		}
+		fmt.Fprintf(&b, "| %d | %d | %d | %s | %s | %s |\n", r.Year, r.Day, r.Part, markdownEscape(answer), formatDuration(r.Duration), markdownEscape(errText))
+	}
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+// markdownEscape stops s breaking out of its table cell
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+// resultFormats are the formats 'run' can write results in
+var resultFormats = map[string]func(w io.Writer) resultWriter{
+	"text":     func(w io.Writer) resultWriter { return textWriter{w: w} },
+	"json":     func(w io.Writer) resultWriter { return &jsonWriter{w: w} },
+	"ndjson":   func(w io.Writer) resultWriter { return ndjsonWriter{enc: json.NewEncoder(w)} },
+	"markdown": func(w io.Writer) resultWriter { return &markdownWriter{w: w} },
+}
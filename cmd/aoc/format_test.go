@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/matryer/is"
+)
+
+// testResults are two days' results, the second one failed part 2
+var testResults = [][]solution.Result{
+	{
+		{Year: 2020, Day: 1, Part: 1, Answer: solution.Int(878724), Duration: 1500 * time.Nanosecond},
+		{Year: 2020, Day: 1, Part: 2, Answer: solution.Text("ABC"), Duration: 2 * time.Millisecond},
+	},
+	{
+		{Year: 2020, Day: 2, Part: 1, Answer: solution.Int(564), Duration: 900},
+		{Year: 2020, Day: 2, Part: 2, Err: errors.New("day 2 part 2: no | way"), Duration: 100},
+	},
+}
+
+// writeAll writes testResults in format and returns the output
+func writeAll(t *testing.T, format string) string {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := resultFormats[format](buf)
+	for _, results := range testResults {
+		if err := w.Write(results); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestFormatText(t *testing.T) {
+	is := is.New(t)
+	is.Equal(writeAll(t, "text"), "Day 1 part 1: 878724\nDay 1 part 2: ABC\nDay 2 part 1: 564\n")
+}
+
+func TestFormatJSON(t *testing.T) {
+	is := is.New(t)
+	want := `{"year":2020,"day":2,"part":2,"answer":"","duration":100,"error":"day 2 part 2: no | way"}`
+
+	lines := strings.Split(strings.TrimSpace(writeAll(t, "ndjson")), "\n")
+	is.Equal(len(lines), 4)
+	is.Equal(lines[0], `{"year":2020,"day":1,"part":1,"answer":"878724","duration":1500,"error":""}`)
+	is.Equal(lines[3], want)
+
+	var decoded []resultJSON
+	is.NoErr(json.Unmarshal([]byte(writeAll(t, "json")), &decoded))
+	is.Equal(len(decoded), 4)
+	is.Equal(decoded[1], resultJSON{Year: 2020, Day: 1, Part: 2, Answer: "ABC", Duration: 2000000})
+
+	// Nothing ran is still valid JSON
+	buf := &bytes.Buffer{}
+	is.NoErr(resultFormats["json"](buf).Flush())
+	is.Equal(buf.String(), "[]\n")
+}
+
+func TestFormatMarkdown(t *testing.T) {
+	is := is.New(t)
+	got := writeAll(t, "markdown")
+
+	is.True(strings.HasPrefix(got, "| Year | Day | Part | Answer | Duration | Error |\n"))
+	is.True(strings.Contains(got, "| 2020 | 1 | 1 | 878724 | 1.5µs |  |\n"))
+	is.True(strings.Contains(got, "| 2020 | 2 | 2 |  | 100ns | day 2 part 2: no \\| way |\n"))
+}
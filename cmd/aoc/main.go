@@ -0,0 +1,94 @@
+// Command aoc runs the solutions to every day of Advent of Code registered with the solution package
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+const usage = `Usage: aoc <command> [flags] [args]
+
+Commands:
+  run     Run a day's solution, or every registered day with 'all'
+  bench   Time every registered day, or a single one
+  verify  Check days still get their recorded answers
+  readme  Update the progress tables in README.md
+  watch   Rerun a day's example tests and solution whenever it changes
+  serve   Solve posted inputs over a local HTTP API
+
+Run 'aoc <command> -h' for a command's flags.`
+
+func main() {
+	log.SetFlags(0)
+	args := os.Args[1:]
+	if err := run(args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "run":
+		return runSolutions(args[1:])
+	case "bench":
+		return runBench(args[1:])
+	case "verify":
+		return runVerify(args[1:])
+	case "readme":
+		return runReadme(args[1:])
+	case "watch":
+		return runWatch(args[1:])
+	case "serve":
+		return runServe(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+}
+
+// yearFlag registers the --year flag on flags, resolve the value with resolveYear after parsing
+func yearFlag(flags *flag.FlagSet) *int {
+	return flags.Int("year", 0, "Year of the event (default $AOC_YEAR or "+strconv.Itoa(utils.YEAR)+")")
+}
+
+// resolveYear fills in a --year that wasn't given from $AOC_YEAR or the default
+func resolveYear(year *int) error {
+	resolved, err := utils.ResolveYear(*year)
+	if err != nil {
+		return err
+	}
+	*year = resolved
+	return nil
+}
+
+// selectDays returns the days arg refers to, a single day or 'all' of year's
+// registered days, checking src makes sense for them
+func selectDays(year int, arg string, src solution.Source) ([]int, error) {
+	if arg != "all" {
+		day, err := utils.ParseDay(arg)
+		if err != nil {
+			return nil, err
+		}
+		return []int{day}, nil
+	}
+
+	days := solution.Days(year)
+	if len(days) == 0 {
+		return nil, fmt.Errorf("no days registered for %d", year)
+	}
+	if src.Path != "" {
+		// Every day's input is different, --example is fine as each day has its own
+		return nil, errors.New("--input can only be used with a single day")
+	}
+	return days, nil
+}
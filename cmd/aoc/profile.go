@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strconv"
+	"strings"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// MEMPROFILERATE is the memory profiling rate while a part is being profiled,
+// 1 records every allocation which is slow but exact
+const MEMPROFILERATE = 1
+
+// profiler writes the profiles asked for around each part of a day, so
+// parsing and the rest of the runner don't show up in them
+type profiler struct {
+	dir     string
+	written []string // Every file written so far
+	errs    []error  // Profiling failures, the part still runs
+	top     int      // How many entries of 'go tool pprof -top' to print, 0 for none
+	cpu     bool
+	mem     bool
+	trace   bool
+}
+
+// register adds the profiling flags to flags
+func (p *profiler) register(flags *flag.FlagSet) {
+	flags.BoolVar(&p.cpu, "cpuprofile", false, "Write a CPU profile of the part to dayNN-partN.cpu.pprof")
+	flags.BoolVar(&p.mem, "memprofile", false, "Write an allocation profile of the part to dayNN-partN.mem.pprof")
+	flags.BoolVar(&p.trace, "trace", false, "Write an execution trace of the part to dayNN-partN.trace")
+	flags.StringVar(&p.dir, "profile-dir", ".", "Where to write profiles")
+	flags.IntVar(&p.top, "pprof-top", 0, "Print the top N entries of each profile written")
+}
+
+// enabled reports whether any profiling was asked for
+func (p *profiler) enabled() bool {
+	return p.cpu || p.mem || p.trace
+}
+
+// start gets the runtime ready, it must be called before anything worth
+// leaving out of the memory profile is allocated
+func (p *profiler) start() error {
+	if p.mem {
+		// Nothing is recorded except while a part is running
+		runtime.MemProfileRate = 0
+	}
+	return os.MkdirAll(p.dir, 0o755)
+}
+
+// hook returns the solution.Hook that profiles the part of day being run
+func (p *profiler) hook(day int) solution.Hook {
+	return func(part int, solve func()) {
+		base := filepath.Join(p.dir, fmt.Sprintf("day%02d-part%d", day, part))
+
+		var stops []func() error
+		if p.cpu {
+			if stop, err := p.startCPU(base + ".cpu.pprof"); err != nil {
+				p.errs = append(p.errs, err)
+			} else {
+				stops = append(stops, stop)
+			}
+		}
+		if p.trace {
+			if stop, err := p.startTrace(base + ".trace"); err != nil {
+				p.errs = append(p.errs, err)
+			} else {
+				stops = append(stops, stop)
+			}
+		}
+		if p.mem {
+			runtime.MemProfileRate = MEMPROFILERATE
+		}
+
+		solve()
+
+		if p.mem {
+			runtime.MemProfileRate = 0
+			stops = append(stops, func() error { return p.writeMem(base + ".mem.pprof") })
+		}
+		for _, stop := range stops {
+			if err := stop(); err != nil {
+				p.errs = append(p.errs, err)
+			}
+		}
+	}
+}
+
+// startCPU starts CPU profiling to path, returning how to stop it
+func (p *profiler) startCPU(path string) (func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := pprof.StartCPUProfile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		pprof.StopCPUProfile()
+		p.written = append(p.written, path)
+		return f.Close()
+	}, nil
+}
+
+// startTrace starts an execution trace to path, returning how to stop it
+func (p *profiler) startTrace(path string) (func() error, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		trace.Stop()
+		p.written = append(p.written, path)
+		return f.Close()
+	}, nil
+}
+
+// writeMem writes everything allocated while profiling was on to path
+func (p *profiler) writeMem(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// The profile is only up to date as of the last GC
+	runtime.GC()
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		f.Close()
+		return err
+	}
+	p.written = append(p.written, path)
+	return f.Close()
+}
+
+// report lists the files written to w, with the top of each profile if asked for
+func (p *profiler) report(w io.Writer) error {
+	for _, path := range p.written {
+		fmt.Fprintf(w, "wrote %s\n", path)
+		if p.top == 0 || filepath.Ext(path) != ".pprof" {
+			// Traces are for 'go tool trace', there's no top
+			continue
+		}
+		args := []string{"tool", "pprof", "-top", "-nodecount=" + strconv.Itoa(p.top)}
+		if strings.HasSuffix(path, ".mem.pprof") {
+			args = append(args, "-sample_index=alloc_space")
+		}
+		cmd := exec.Command("go", append(args, path)...)
+		cmd.Stdout, cmd.Stderr = w, w
+		if err := cmd.Run(); err != nil {
+			p.errs = append(p.errs, fmt.Errorf("go tool pprof %s: %w", path, err))
+		}
+	}
+	if len(p.errs) > 0 {
+		// Usually the same thing going wrong for every part
+		return fmt.Errorf("profiling: %w", p.errs[0])
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/matryer/is"
+)
+
+func TestProfiler(t *testing.T) {
+	is := is.New(t)
+	rate := runtime.MemProfileRate
+	t.Cleanup(func() { runtime.MemProfileRate = rate })
+
+	prof := &profiler{dir: filepath.Join(t.TempDir(), "profiles"), cpu: true, mem: true, trace: true}
+	is.True(prof.enabled())
+	is.NoErr(prof.start())
+
+	puzzle := solution.Puzzle{Year: 1999, Day: 6, New: func() solution.Solution { return &words{} }}
+	results := puzzle.RunHook(context.Background(), strings.NewReader("a b\n"), 2, prof.hook(6))
+	is.Equal(len(results), 1)
+	is.NoErr(results[0].Err)
+
+	for _, name := range []string{"day06-part2.cpu.pprof", "day06-part2.mem.pprof", "day06-part2.trace"} {
+		info, err := os.Stat(filepath.Join(prof.dir, name))
+		is.NoErr(err)
+		is.True(info.Size() > 0)
+	}
+
+	buf := &bytes.Buffer{}
+	is.NoErr(prof.report(buf))
+	is.Equal(strings.Count(buf.String(), "wrote "), 3)
+	is.True(!(&profiler{}).enabled())
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// The markers around the part of README.md that 'readme' owns, anything outside them is left alone
+const (
+	readmeStart = "<!-- aoc:progress:start -->"
+	readmeEnd   = "<!-- aoc:progress:end -->"
+)
+
+// titleRegex matches a day's title in the doc comment at the top of its dayNN.go
+var titleRegex = regexp.MustCompile(`--- Day \d+: (.+?) ---`)
+
+// progress is one day's row in the README table
+type progress struct {
+	title string // "" if the day has no doc comment to take it from
+	path  string // The solution file relative to the root, with forward slashes
+	known solution.Answers
+	timed timings
+	day   int
+}
+
+// stars returns how many parts have a known answer
+func (p progress) stars() int {
+	n := 0
+	if p.known.Part1 != "" {
+		n++
+	}
+	if p.known.Part2 != "" {
+		n++
+	}
+	return n
+}
+
+// dayTitle returns the puzzle title from the doc comment in a day's source, or ""
+func dayTitle(src []byte) string {
+	match := titleRegex.FindSubmatch(src)
+	if match == nil {
+		return ""
+	}
+	return string(match[1])
+}
+
+// loadProgress gathers everything the README says about year's day under root
+func loadProgress(root string, year, day int) (progress, error) {
+	name := fmt.Sprintf("day%02d", day)
+	path := filepath.Join(fmt.Sprint(year), name, name+".go")
+	p := progress{day: day, path: filepath.ToSlash(path)}
+
+	src, err := os.ReadFile(filepath.Join(root, path))
+	if err != nil {
+		return progress{}, err
+	}
+	p.title = dayTitle(src)
+
+	p.known, err = solution.LoadAnswers(year, day)
+	if err != nil && !errors.Is(err, solution.ErrNoAnswers) {
+		return progress{}, err
+	}
+	p.timed, err = loadTimings(year, day)
+	if err != nil {
+		return progress{}, err
+	}
+	return p, nil
+}
+
+// yearProgress is one year's days in the README
+type yearProgress struct {
+	days []progress
+	year int
+}
+
+// loadYears gathers the README rows for every registered year under root, oldest first
+func loadYears(root string) ([]yearProgress, error) {
+	var years []yearProgress
+	for _, year := range solution.Years() {
+		y := yearProgress{year: year}
+		for _, day := range solution.Days(year) {
+			p, err := loadProgress(root, year, day)
+			if err != nil {
+				return nil, err
+			}
+			y.days = append(y.days, p)
+		}
+		years = append(years, y)
+	}
+	return years, nil
+}
+
+// renderProgress returns the README section with a table for each year, including the markers
+func renderProgress(years []yearProgress) []byte {
+	var b bytes.Buffer
+	b.WriteString(readmeStart + "\n")
+	b.WriteString("<!-- Generated by 'go run ./cmd/aoc readme', edits between these markers will be lost -->\n\n")
+	for _, y := range years {
+		renderYear(&b, y)
+	}
+	b.WriteString("Runtimes are the medians recorded with `aoc bench --record`\n")
+	b.WriteString(readmeEnd + "\n")
+	return b.Bytes()
+}
+
+// renderYear writes y's heading, table and star count to b
+func renderYear(b *bytes.Buffer, y yearProgress) {
+	fmt.Fprintf(b, "### %d\n\n", y.year)
+	b.WriteString("| Day | Puzzle | Stars | Part 1 | Part 2 | Solution |\n")
+	b.WriteString("|----:|:-------|:------|-------:|-------:|:---------|\n")
+	stars := 0
+	for _, d := range y.days {
+		title := d.title
+		if title == "" {
+			title = fmt.Sprintf("Day %d", d.day)
+		}
+		stars += d.stars()
+		fmt.Fprintf(b, "| %d | [%s](https://adventofcode.com/%d/day/%d) | %s | %s | %s | [%s](%s) |\n",
+			d.day, markdownEscape(title), y.year, d.day, strings.Repeat("⭐", d.stars()),
+			readmeDuration(d.known.Part1, d.timed.Part1), readmeDuration(d.known.Part2, d.timed.Part2),
+			filepath.Base(d.path), d.path,
+		)
+	}
+	fmt.Fprintf(b, "\n%d/50 stars\n\n", stars)
+}
+
+// readmeDuration is a part's runtime for the table, or "-" if it hasn't been solved or timed
+func readmeDuration(answer string, d time.Duration) string {
+	if answer == "" || d == 0 {
+		return "-"
+	}
+	return formatDuration(d)
+}
+
+// replaceSection swaps the marked section of doc for section, or adds section
+// to the end if doc has no markers yet
+func replaceSection(doc, section []byte) ([]byte, error) {
+	start := bytes.Index(doc, []byte(readmeStart))
+	end := bytes.Index(doc, []byte(readmeEnd))
+
+	switch {
+	case start == -1 && end == -1:
+		out := bytes.TrimRight(doc, "\n")
+		out = append(out[:len(out):len(out)], "\n\n"...)
+		return append(out, section...), nil
+	case start == -1 || end == -1 || end < start:
+		return nil, fmt.Errorf("README has mismatched %s and %s markers", readmeStart, readmeEnd)
+	}
+
+	end += len(readmeEnd)
+	if end < len(doc) && doc[end] == '\n' {
+		end++
+	}
+	out := make([]byte, 0, len(doc)+len(section))
+	out = append(out, doc[:start]...)
+	out = append(out, section...)
+	return append(out, doc[end:]...), nil
+}
+
+// runReadme implements the 'readme' command
+func runReadme(args []string) error {
+	flags := flag.NewFlagSet("readme", flag.ContinueOnError)
+	check := flags.Bool("check", false, "Don't write anything, fail if the README is out of date")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("readme takes no args, got: %v", flags.Args())
+	}
+
+	root := utils.Root()
+	years, err := loadYears(root)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(root, "README.md")
+	doc, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	updated, err := replaceSection(doc, renderProgress(years))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case bytes.Equal(doc, updated):
+		fmt.Println("README.md is up to date")
+		return nil
+	case *check:
+		return errors.New("README.md is out of date, run 'aoc readme' to update it")
+	}
+	if err := os.WriteFile(path, updated, 0o644); err != nil {
+		return err
+	}
+	fmt.Println("updated README.md")
+	return nil
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/matryer/is"
+)
+
+func TestDayTitle(t *testing.T) {
+	is := is.New(t)
+	is.Equal(dayTitle([]byte("/*\n--- Day 4: Passport Processing ---\n\nYou arrive...\n*/\npackage day04\n")), "Passport Processing")
+	is.Equal(dayTitle([]byte("package day04\n")), "")
+}
+
+func TestRenderProgress(t *testing.T) {
+	is := is.New(t)
+	days := []progress{
+		{day: 1, title: "Report Repair", path: "2020/day01/day01.go", known: solution.Answers{Part1: "1", Part2: "2"}, timed: timings{Part1: 1500, Part2: 2 * time.Millisecond}},
+		{day: 2, path: "2020/day02/day02.go", known: solution.Answers{Part1: "3", Part2: "4"}, timed: timings{Part1: 900}},
+	}
+
+	got := string(renderProgress([]yearProgress{{year: 2020, days: days}}))
+	is.True(strings.HasPrefix(got, readmeStart+"\n"))
+	is.True(strings.HasSuffix(got, readmeEnd+"\n"))
+	is.True(strings.Contains(got, "| 1 | [Report Repair](https://adventofcode.com/2020/day/1) | ⭐⭐ | 1.5µs | 2ms | [day01.go](2020/day01/day01.go) |\n"))
+	is.True(strings.Contains(got, "| 2 | [Day 2](https://adventofcode.com/2020/day/2) | ⭐⭐ | 900ns | - | [day02.go](2020/day02/day02.go) |\n"))
+	is.True(strings.Contains(got, "\n4/50 stars\n"))
+}
+
+func TestRenderProgressYears(t *testing.T) {
+	is := is.New(t)
+	years := []yearProgress{
+		{year: 2020, days: []progress{{day: 1, path: "2020/day01/day01.go", known: solution.Answers{Part1: "1", Part2: "2"}}}},
+		{year: 2021, days: []progress{{day: 1, path: "2021/day01/day01.go", known: solution.Answers{Part1: "3"}}}},
+	}
+
+	got := string(renderProgress(years))
+	first, second := strings.Index(got, "### 2020\n"), strings.Index(got, "### 2021\n")
+	is.True(first != -1 && second > first) // Each year gets a heading, in order
+	is.True(strings.Contains(got[first:second], "| 1 | [Day 1](https://adventofcode.com/2020/day/1) | ⭐⭐ |"))
+	is.True(strings.Contains(got[first:second], "\n2/50 stars\n"))
+	is.True(strings.Contains(got[second:], "| 1 | [Day 1](https://adventofcode.com/2021/day/1) | ⭐ |"))
+	is.True(strings.Contains(got[second:], "\n1/50 stars\n"))
+
+	// --check compares the whole section, so a change to either year shows up
+	doc, err := replaceSection([]byte("# Title\n"), []byte(got))
+	is.NoErr(err)
+	years[0].days[0].known.Part2 = ""
+	updated, err := replaceSection(doc, renderProgress(years))
+	is.NoErr(err)
+	is.True(string(updated) != string(doc))
+}
+
+func TestReplaceSection(t *testing.T) {
+	is := is.New(t)
+	section := []byte(readmeStart + "\nnew\n" + readmeEnd + "\n")
+
+	// No markers yet, it goes on the end
+	got, err := replaceSection([]byte("# Title\n\nIntro\n"), section)
+	is.NoErr(err)
+	is.Equal(string(got), "# Title\n\nIntro\n\n"+string(section))
+
+	// Replacing keeps everything either side and doing it again changes nothing
+	doc := []byte("# Title\n\n" + readmeStart + "\nold\n" + readmeEnd + "\n\n## After\n")
+	once, err := replaceSection(doc, section)
+	is.NoErr(err)
+	is.Equal(string(once), "# Title\n\n"+string(section)+"\n## After\n")
+	twice, err := replaceSection(once, section)
+	is.NoErr(err)
+	is.Equal(twice, once)
+
+	_, err = replaceSection([]byte(readmeEnd+"\n"+readmeStart+"\n"), section)
+	is.True(err != nil) // Backwards
+}
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// runSolutions implements the 'run' command
+func runSolutions(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	year := yearFlag(flags)
+	part := flags.Int("part", 0, "Only run this part, 1 or 2 (default both)")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "How many days to run at once")
+	timeout := flags.Duration("timeout", 0, "Give up on a day that takes longer than this e.g. 30s (default no limit)")
+	format := flags.String("format", "text", "Output format: text, json, ndjson or markdown, durations are in nanoseconds")
+	var src solution.Source
+	src.RegisterFlags(flags)
+	var prof profiler
+	prof.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := resolveYear(year); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) != 1 {
+		return fmt.Errorf("run expects a single arg 'day' or 'all', got: %v", args)
+	}
+	if *part < 0 || *part > 2 {
+		return fmt.Errorf("part should be 1 or 2, got: %d", *part)
+	}
+	if *jobs < 1 {
+		return fmt.Errorf("jobs should be at least 1, got: %d", *jobs)
+	}
+	newWriter, ok := resultFormats[*format]
+	if !ok {
+		return fmt.Errorf("unknown format %q, should be text, json, ndjson or markdown", *format)
+	}
+	if err := src.Validate(); err != nil {
+		return err
+	}
+
+	days, err := selectDays(*year, args[0], src)
+	if err != nil {
+		return err
+	}
+
+	opts := runOptions{src: src, part: *part, jobs: *jobs, timeout: *timeout}
+	if prof.enabled() {
+		// The allocation profile can't be reset, so it's one part per run to keep them apart
+		if len(days) != 1 || *part == 0 {
+			return errors.New("profiling is for a single day's part, pick a day and a --part")
+		}
+		if err := prof.start(); err != nil {
+			return err
+		}
+		opts.hook = prof.hook(days[0])
+	}
+
+	// Ctrl-C stops what's running and reports what's done, a second one kills us as normal
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	collected := &runCollector{out: newWriter(os.Stdout), keepGoing: len(days) > 1}
+	runDays(ctx, *year, days, opts, collected.add)
+	collected.keep(collected.out.Flush())
+	if prof.enabled() {
+		// Stderr so it doesn't get mixed up with the results
+		collected.keep(prof.report(os.Stderr))
+	}
+	return collected.err(ctx, len(days))
+}
+
+// runCollector writes each day's results as they're reported and keeps count of how they went
+type runCollector struct {
+	out       resultWriter
+	last      error // The latest day's error
+	writeErr  error // The first error writing the results
+	failed    int
+	finished  int
+	keepGoing bool // Running more than one day
+}
+
+// add writes a day's results and counts it
+func (c *runCollector) add(r *dayResult) {
+	if r.skipped {
+		return
+	}
+	c.keep(c.out.Write(r.results))
+	if !r.interrupted() {
+		c.finished++
+	}
+	if err := r.err(); err != nil {
+		c.failed++
+		c.last = err
+		if c.keepGoing {
+			// Keep going, one broken day shouldn't hide the rest
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+// keep holds on to err if it's the first error writing the results
+func (c *runCollector) keep(err error) {
+	if err != nil && c.writeErr == nil {
+		c.writeErr = err
+	}
+}
+
+// err sums up the run of total days for the exit status
+func (c *runCollector) err(ctx context.Context, total int) error {
+	switch {
+	case c.writeErr != nil:
+		return c.writeErr
+	case ctx.Err() != nil:
+		return fmt.Errorf("interrupted, %d of %d days finished", c.finished, total)
+	case total == 1:
+		return c.last
+	case c.failed > 0:
+		return fmt.Errorf("%d of %d days failed", c.failed, total)
+	default:
+		return nil
+	}
+}
+
+// dayResult is the outcome of running one day
+type dayResult struct {
+	results []solution.Result
+	day     int
+	skipped bool // Cancelled before it started
+}
+
+// err returns the first error from any part of the day
+func (r *dayResult) err() error {
+	for _, result := range r.results {
+		if result.Err != nil {
+			return result.Err
+		}
+	}
+	return nil
+}
+
+// interrupted reports whether the day didn't get to finish because the run was cancelled
+func (r *dayResult) interrupted() bool {
+	return r.skipped || errors.Is(r.err(), context.Canceled)
+}
+
+// runOptions control how days are run
+type runOptions struct {
+	hook    solution.Hook // Called around each part, may be nil
+	src     solution.Source
+	part    int           // 0 for both
+	jobs    int           // How many days to run at once
+	timeout time.Duration // Per day, 0 for no limit
+}
+
+// runDays solves each of year's days as opts says
+//
+// report is called with each result in day order, as soon as that day and
+// every one before it is done
+func runDays(ctx context.Context, year int, days []int, opts runOptions, report func(r *dayResult)) {
+	results := make([]chan *dayResult, len(days))
+	for i := range results {
+		results[i] = make(chan *dayResult, 1)
+	}
+
+	next := make(chan int)
+	go func() {
+		for i := range days {
+			next <- i
+		}
+		close(next)
+	}()
+
+	for w := 0; w < opts.jobs; w++ {
+		go func() {
+			for i := range next {
+				results[i] <- solveDay(ctx, year, days[i], opts)
+			}
+		}()
+	}
+
+	for _, result := range results {
+		report(<-result)
+	}
+}
+
+// solveDay runs year's day as opts says
+func solveDay(ctx context.Context, year, day int, opts runOptions) *dayResult {
+	r := &dayResult{day: day}
+	if err := ctx.Err(); err != nil {
+		r.results = solution.Failed(year, day, opts.part, err)
+		r.skipped = true
+		return r
+	}
+
+	if opts.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.timeout)
+		defer cancel()
+	}
+
+	puzzle, err := solution.Get(year, day)
+	if err != nil {
+		r.results = solution.Failed(year, day, opts.part, err)
+		return r
+	}
+	input, err := opts.src.Open(year, day)
+	if err != nil {
+		r.results = solution.Failed(year, day, opts.part, err)
+		return r
+	}
+	defer input.Close()
+
+	r.results = puzzle.RunHook(ctx, input, opts.part, opts.hook)
+	explainTimeouts(r.results, day, opts.timeout)
+	return r
+}
+
+// explainTimeouts replaces the error of any part that ran out of time with one saying how long it had
+func explainTimeouts(results []solution.Result, day int, timeout time.Duration) {
+	for i, result := range results {
+		if errors.Is(result.Err, context.DeadlineExceeded) {
+			results[i].Err = fmt.Errorf("day %d part %d: timed out after %s", day, result.Part, timeout)
+		}
+	}
+}
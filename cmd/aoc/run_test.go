@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/matryer/is"
+)
+
+// slow is a solution whose part 2 takes far too long and never checks ctx
+type slow struct {
+	words
+}
+
+func (s *slow) Part2(_ context.Context) (solution.Answer, error) {
+	time.Sleep(time.Second)
+	return solution.Int(0), nil
+}
+
+func init() {
+	solution.Register(1999, 1, func() solution.Solution { return &words{} })
+	solution.Register(1999, 2, func() solution.Solution { return &slow{} })
+	solution.Register(1999, 3, func() solution.Solution { return &words{} })
+}
+
+func TestEveryDayLinked(t *testing.T) {
+	is := is.New(t)
+
+	// Every day on disk, found the same way scripts/link.go finds them for days.go
+	matches, err := filepath.Glob(filepath.Join(utils.Root(), "[0-9][0-9][0-9][0-9]", "day[0-9][0-9]", "day[0-9][0-9].go"))
+	is.NoErr(err)
+	is.True(len(matches) > 0) // No days found on disk
+
+	onDisk := make(map[int][]int)
+	for _, match := range matches {
+		dir := filepath.Dir(match)
+		year, err := strconv.Atoi(filepath.Base(filepath.Dir(dir)))
+		is.NoErr(err)
+		day, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "day"))
+		is.NoErr(err)
+		onDisk[year] = append(onDisk[year], day)
+	}
+
+	for year, days := range onDisk {
+		sort.Ints(days)
+		is.Equal(solution.Days(year), days) // days.go is out of date, 'go run ./scripts new' regenerates it
+	}
+}
+
+// testSource returns a source for a file holding input
+func testSource(t *testing.T, input string) solution.Source {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, []byte(input), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return solution.Source{Path: path}
+}
+
+func TestRunDays(t *testing.T) {
+	is := is.New(t)
+	src := testSource(t, "a b\n")
+
+	var results []*dayResult
+	runDays(context.Background(), 1999, []int{1, 2, 3}, runOptions{src: src, jobs: 2, timeout: 50 * time.Millisecond}, func(r *dayResult) {
+		results = append(results, r)
+	})
+
+	is.Equal(len(results), 3)
+	for i, r := range results {
+		is.Equal(r.day, i+1) // In day order whatever order they finished in
+	}
+
+	is.NoErr(results[0].err())
+	is.Equal(len(results[0].results), 2)
+	is.Equal(results[0].results[1].Answer.String(), "a,b")
+
+	// Day 2 keeps the part that did finish
+	is.Equal(results[1].err().Error(), "day 2 part 2: timed out after 50ms")
+	is.Equal(results[1].results[0].Answer.String(), "2")
+	is.NoErr(results[1].results[0].Err)
+
+	is.NoErr(results[2].err())
+}
+
+func TestRunDaysCancelled(t *testing.T) {
+	is := is.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	n := 0
+	runDays(ctx, 1999, []int{1, 3}, runOptions{src: testSource(t, "a\n"), part: 1, jobs: 4}, func(r *dayResult) {
+		n++
+		is.True(r.skipped)
+		is.True(r.interrupted())
+		is.Equal(len(r.results), 1) // Just the part asked for
+	})
+	is.Equal(n, 2)
+}
+
+func TestSelectDays(t *testing.T) {
+	is := is.New(t)
+
+	days, err := selectDays(1999, "all", solution.Source{Example: 1})
+	is.NoErr(err)
+	is.Equal(days, []int{1, 2, 3})
+
+	days, err = selectDays(1999, "7", solution.Source{})
+	is.NoErr(err)
+	is.Equal(days, []int{7})
+
+	_, err = selectDays(1999, "all", solution.Source{Path: "input.txt"})
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "single day"))
+
+	_, err = selectDays(1998, "all", solution.Source{})
+	is.True(err != nil) // Nothing registered
+
+	_, err = selectDays(1999, "26", solution.Source{})
+	is.True(err != nil)
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// MAXINPUT is the default limit on the size of a posted input, real ones are a few KB
+const MAXINPUT = 10 << 20
+
+// solveRegex matches the path to solve a part e.g. /2020/day/1/part/2
+var solveRegex = regexp.MustCompile(`^/(\d{4})/day/(\d+)/part/(\d+)$`)
+
+// dayJSON is a registered day in the listing
+type dayJSON struct {
+	Parts []string `json:"parts"` // Where to POST an input to solve each part
+	Year  int      `json:"year"`
+	Day   int      `json:"day"`
+}
+
+// errorJSON is the body of any response that isn't a result
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+// server exposes the registry over HTTP
+type server struct {
+	timeout  time.Duration // Per request
+	maxInput int64         // Bytes
+}
+
+// ServeHTTP routes a request, there are few enough that a mux isn't worth it
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/" {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			methodNotAllowed(w, "GET, HEAD")
+			return
+		}
+		s.list(w)
+		return
+	}
+
+	match := solveRegex.FindStringSubmatch(r.URL.Path)
+	if match == nil {
+		writeJSON(w, http.StatusNotFound, errorJSON{Error: fmt.Sprintf("no such endpoint %s, GET / lists what there is", r.URL.Path)})
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, http.MethodPost)
+		return
+	}
+
+	// The regex means these are all numbers
+	year, _ := strconv.Atoi(match[1])
+	day, _ := strconv.Atoi(match[2])
+	part, _ := strconv.Atoi(match[3])
+	s.solve(w, r, year, day, part)
+}
+
+// list writes every registered day and where to solve it
+func (s *server) list(w http.ResponseWriter) {
+	days := []dayJSON{}
+	for _, year := range solution.Years() {
+		for _, day := range solution.Days(year) {
+			d := dayJSON{Year: year, Day: day}
+			for _, part := range solution.Parts(0) {
+				d.Parts = append(d.Parts, fmt.Sprintf("/%d/day/%d/part/%d", year, day, part))
+			}
+			days = append(days, d)
+		}
+	}
+	writeJSON(w, http.StatusOK, days)
+}
+
+// solve runs a part of a day on the request body and writes the result
+func (s *server) solve(w http.ResponseWriter, r *http.Request, year, day, part int) {
+	if part < 1 || part > 2 {
+		writeJSON(w, http.StatusBadRequest, errorJSON{Error: fmt.Sprintf("part should be 1 or 2, got: %d", part)})
+		return
+	}
+	puzzle, err := solution.Get(year, day)
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, errorJSON{Error: err.Error()})
+		return
+	}
+
+	// Read one byte past the limit so too big can be told from exactly big enough
+	input, err := io.ReadAll(io.LimitReader(r.Body, s.maxInput+1))
+	switch {
+	case err != nil:
+		writeJSON(w, http.StatusBadRequest, errorJSON{Error: fmt.Sprintf("could not read input: %v", err)})
+		return
+	case int64(len(input)) > s.maxInput:
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorJSON{Error: fmt.Sprintf("input should be at most %d bytes", s.maxInput)})
+		return
+	}
+	if len(bytes.TrimSpace(input)) == 0 {
+		writeJSON(w, http.StatusBadRequest, errorJSON{Error: "post the puzzle input as the request body"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+	defer cancel()
+	result := puzzle.Run(ctx, bytes.NewReader(input), part)[0]
+
+	status := http.StatusOK
+	switch {
+	case errors.Is(result.Err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+		result.Err = fmt.Errorf("day %d part %d: timed out after %s", day, part, s.timeout)
+	case result.Err != nil:
+		// Most likely the input isn't for this day
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(w, status, toJSON(result))
+}
+
+// methodNotAllowed tells the client which methods it should have used
+func methodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	writeJSON(w, http.StatusMethodNotAllowed, errorJSON{Error: "method should be " + allow})
+}
+
+// writeJSON writes v as the JSON response body with status
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("could not write response: %v", err)
+	}
+}
+
+// runServe implements the 'serve' command
+func runServe(args []string) error {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := flags.String("addr", "localhost:8080", "Address to listen on, the default only accepts local connections")
+	timeout := flags.Duration("timeout", 30*time.Second, "Give up on a request that takes longer than this")
+	maxInput := flags.Int64("max-input", MAXINPUT, "Largest input accepted, in bytes")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("serve takes no args, got: %v", flags.Args())
+	}
+	if *timeout <= 0 || *maxInput <= 0 {
+		return errors.New("timeout and max-input should be positive")
+	}
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           &server{timeout: *timeout, maxInput: *maxInput},
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+	log.Printf("serving on http://%s, GET / lists the days, Ctrl-C to stop", *addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	// Let requests in flight finish, within reason
+	shutdown, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	return srv.Shutdown(shutdown)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// serve sends a request to a test server and returns the response
+func serve(t *testing.T, method, path, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	s := &server{timeout: 50 * time.Millisecond, maxInput: 32}
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func TestServeSolve(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		answer string
+		err    string
+	}{
+		{name: "part 1", method: "POST", path: "/1999/day/1/part/1", body: "a b c", status: http.StatusOK, answer: "3"},
+		{name: "part 2", method: "POST", path: "/1999/day/3/part/2", body: "a b", status: http.StatusOK, answer: "a,b"},
+		{name: "empty input", method: "POST", path: "/1999/day/1/part/2", body: "\n\n", status: http.StatusBadRequest, err: "post the puzzle input"},
+		{name: "wrong input", method: "POST", path: "/2020/day/1/part/1", body: "not numbers", status: http.StatusUnprocessableEntity, err: "day 1"},
+		{name: "timeout", method: "POST", path: "/1999/day/2/part/2", body: "a", status: http.StatusGatewayTimeout, err: "timed out after 50ms"},
+		{name: "unregistered", method: "POST", path: "/1999/day/4/part/1", body: "a", status: http.StatusNotFound, err: "1999"},
+		{name: "bad part", method: "POST", path: "/1999/day/1/part/3", body: "a", status: http.StatusBadRequest, err: "part should be 1 or 2"},
+		{name: "too big", method: "POST", path: "/1999/day/1/part/1", body: strings.Repeat("a ", 17), status: http.StatusRequestEntityTooLarge, err: "at most 32 bytes"},
+		{name: "get", method: "GET", path: "/1999/day/1/part/1", status: http.StatusMethodNotAllowed, err: "POST"},
+		{name: "no such endpoint", method: "GET", path: "/1999/day/1", status: http.StatusNotFound, err: "no such endpoint"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			rec := serve(t, tt.method, tt.path, tt.body)
+			is.Equal(rec.Code, tt.status)
+			is.Equal(rec.Header().Get("Content-Type"), "application/json")
+
+			var got struct {
+				resultJSON
+				Error string `json:"error"`
+			}
+			is.NoErr(json.Unmarshal(rec.Body.Bytes(), &got))
+			is.Equal(got.Answer, tt.answer)
+			is.True(strings.Contains(got.Error, tt.err)) // Error doesn't say what went wrong
+			if tt.status == http.StatusOK {
+				is.Equal(got.Year, 1999)
+				is.True(got.Duration > 0)
+			}
+		})
+	}
+}
+
+func TestServeList(t *testing.T) {
+	is := is.New(t)
+
+	rec := serve(t, "GET", "/", "")
+	is.Equal(rec.Code, http.StatusOK)
+
+	var days []dayJSON
+	is.NoErr(json.Unmarshal(rec.Body.Bytes(), &days))
+	is.True(len(days) > 3)
+	is.Equal(days[0], dayJSON{Year: 1999, Day: 1, Parts: []string{"/1999/day/1/part/1", "/1999/day/1/part/2"}})
+	is.Equal(days[len(days)-1].Year, 2020) // Years out of order
+
+	rec = serve(t, "POST", "/", "")
+	is.Equal(rec.Code, http.StatusMethodNotAllowed)
+	is.Equal(rec.Header().Get("Allow"), "GET, HEAD")
+}
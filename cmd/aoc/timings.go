@@ -0,0 +1,68 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// timings are a day's runtimes for the README, the medians from 'bench --record'
+//
+// They're kept apart from the answers so recording answers doesn't churn them,
+// and only change when someone sets out to measure
+type timings struct {
+	Runs  int           `json:"runs"`
+	Part1 time.Duration `json:"part1_median_ns"`
+	Part2 time.Duration `json:"part2_median_ns"`
+}
+
+// timingsPath returns where year's day keeps its timings
+func timingsPath(year, day int) string {
+	return filepath.Join(utils.Dir(year, day), "timings.json")
+}
+
+// loadTimings returns the recorded timings for year's day, zero if there aren't any
+func loadTimings(year, day int) (timings, error) {
+	data, err := os.ReadFile(timingsPath(year, day))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return timings{}, nil
+		}
+		return timings{}, err
+	}
+
+	var t timings
+	if err := json.Unmarshal(data, &t); err != nil {
+		return timings{}, fmt.Errorf("%s: %w", timingsPath(year, day), err)
+	}
+	return t, nil
+}
+
+// saveTimings records t for year's day, replacing any already there
+func saveTimings(year, day int, t timings) error {
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(timingsPath(year, day), append(data, '\n'), 0o644)
+}
+
+// timingsFrom picks the part medians out of a day's bench stats
+func timingsFrom(stats []phaseStats) timings {
+	var t timings
+	for _, s := range stats {
+		switch s.Phase {
+		case phasePart1:
+			t.Part1, t.Runs = s.Median, s.Runs
+		case phasePart2:
+			t.Part2, t.Runs = s.Median, s.Runs
+		}
+	}
+	return t
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// verifyDay checks year's day still gets its recorded answers on its puzzle input
+func verifyDay(ctx context.Context, year, day int) error {
+	puzzle, err := solution.Get(year, day)
+	if err != nil {
+		return err
+	}
+	want, err := solution.LoadAnswers(year, day)
+	if err != nil {
+		return err
+	}
+	input, err := os.ReadFile(solution.InputPath(year, day))
+	if err != nil {
+		return err
+	}
+	return puzzle.Verify(ctx, input, want)
+}
+
+// recordDay solves year's day on its puzzle input and records the answers as the right ones
+func recordDay(ctx context.Context, w io.Writer, year, day int) error {
+	puzzle, err := solution.Get(year, day)
+	if err != nil {
+		return err
+	}
+	input, err := os.ReadFile(solution.InputPath(year, day))
+	if err != nil {
+		return err
+	}
+	answers, err := puzzle.Answers(ctx, input)
+	if err != nil {
+		return err
+	}
+	if err := solution.SaveAnswers(year, day, answers); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Day %d recorded: part 1 %s, part 2 %s\n", day, answers.Part1, answers.Part2)
+	return nil
+}
+
+// runVerify implements the 'verify' command
+func runVerify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	year := yearFlag(flags)
+	update := flags.Bool("update", false, "Record the current answers as the right ones instead of checking them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := resolveYear(year); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) > 1 {
+		return fmt.Errorf("verify expects at most one arg 'day' or 'all', got: %v", args)
+	}
+	which := "all"
+	if len(args) == 1 {
+		which = args[0]
+	}
+
+	days, err := selectDays(*year, which, solution.Source{})
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	failed := 0
+	for _, day := range days {
+		if *update {
+			err = recordDay(ctx, os.Stdout, *year, day)
+		} else {
+			err = verifyDay(ctx, *year, day)
+			switch {
+			case err == nil:
+				fmt.Printf("Day %d ok\n", day)
+			case errors.Is(err, solution.ErrNoAnswers):
+				// Not a failure, the day just isn't solved yet
+				fmt.Printf("Day %d skipped, no answers recorded\n", day)
+				err = nil
+			}
+		}
+		if err != nil {
+			if len(days) == 1 {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(days))
+	}
+	return nil
+}
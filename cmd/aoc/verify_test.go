@@ -0,0 +1,28 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/solution"
+)
+
+// TestAnswers checks every day still gets the answers recorded with 'aoc verify --update'
+func TestAnswers(t *testing.T) {
+	for _, year := range solution.Years() {
+		for _, day := range solution.Days(year) {
+			year, day := year, day
+			t.Run(fmt.Sprintf("%d/day%02d", year, day), func(t *testing.T) {
+				err := verifyDay(context.Background(), year, day)
+				if errors.Is(err, solution.ErrNoAnswers) {
+					t.Skip(err)
+				}
+				if err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+}
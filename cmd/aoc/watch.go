@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/testjson"
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// fileState is what we look at to tell whether a file has changed, there's
+// no fsnotify in go.mod so watching is done by polling
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// snapshot returns the state of every Go file and text file (inputs and examples) under dir
+func snapshot(dir string) (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".go" && ext != ".txt" {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// changedFiles returns every file that's been added, removed or modified between before and after, sorted
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if old, ok := before[path]; !ok || old != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// watchDir polls dir on every tick and calls onChange with everything that
+// changed once things have been quiet for debounce, so saving several files
+// at once only triggers one run
+//
+// Time is whatever the ticks say it is, so tests don't have to sleep. It
+// returns when ctx is done
+func watchDir(ctx context.Context, dir string, ticks <-chan time.Time, debounce time.Duration, onChange func(changed []string)) error {
+	last, err := snapshot(dir)
+	if err != nil {
+		return err
+	}
+
+	var (
+		pending    = make(map[string]bool)
+		lastChange time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticks:
+			current, err := snapshot(dir)
+			if err != nil {
+				// Editors often replace files by removing and renaming, try again next tick
+				continue
+			}
+			if changed := changedFiles(last, current); len(changed) > 0 {
+				for _, path := range changed {
+					pending[path] = true
+				}
+				lastChange = now
+			}
+			last = current
+
+			if len(pending) > 0 && now.Sub(lastChange) >= debounce {
+				paths := make([]string, 0, len(pending))
+				for path := range pending {
+					paths = append(paths, path)
+				}
+				sort.Strings(paths)
+				pending = make(map[string]bool)
+				onChange(paths)
+			}
+		}
+	}
+}
+
+// testSummary is what happened in a run of a package's tests
+type testSummary struct {
+	output  map[string][]string // What each failing test printed
+	passed  []string
+	failed  []string
+	skipped []string // Scaffolded example tests skip until their answer is filled in
+}
+
+// parseTestEvents summarises a stream of `go test -json` events
+func parseTestEvents(r io.Reader) (testSummary, error) {
+	summary := testSummary{output: make(map[string][]string)}
+	err := testjson.Read(r, func(event testjson.Event) {
+		switch event.Action {
+		case "output":
+			summary.output[event.Test] = append(summary.output[event.Test], event.Output)
+		case "pass":
+			summary.passed = append(summary.passed, event.Test)
+		case "fail":
+			summary.failed = append(summary.failed, event.Test)
+		case "skip":
+			summary.skipped = append(summary.skipped, event.Test)
+		}
+	})
+	return summary, err
+}
+
+// print writes the summary compactly, only failures get their output shown
+func (s testSummary) print(w io.Writer) {
+	skipped := ""
+	if len(s.skipped) > 0 {
+		skipped = fmt.Sprintf(", %d skipped", len(s.skipped))
+	}
+	switch {
+	case len(s.failed) == 0 && len(s.passed) == 0 && len(s.skipped) > 0:
+		// Nothing to say it works yet, don't call that a pass
+		fmt.Fprintf(w, "  tests   SKIP  %d skipped\n", len(s.skipped))
+		return
+	case len(s.failed) == 0:
+		fmt.Fprintf(w, "  tests   PASS  %d passed%s\n", len(s.passed), skipped)
+		return
+	}
+	fmt.Fprintf(w, "  tests   FAIL  %d passed, %d failed%s\n", len(s.passed), len(s.failed), skipped)
+	for _, test := range s.failed {
+		for _, line := range s.output[test] {
+			if strings.HasPrefix(strings.TrimSpace(line), "=== RUN") || strings.HasPrefix(strings.TrimSpace(line), "--- FAIL") {
+				continue
+			}
+			fmt.Fprintf(w, "          %s", line)
+		}
+	}
+}
+
+// watcher reruns a day's example tests and solution
+type watcher struct {
+	out     io.Writer
+	root    string
+	pkg     string // The day's package relative to root e.g. ./2020/day03
+	command string // The day's own main package, which is rebuilt every time
+	timeout time.Duration
+	example int // Run the solution on this example rather than the real input, 0 for the real input
+}
+
+// run does one round of testing and solving
+func (w *watcher) run(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, w.timeout)
+	defer cancel()
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	test := exec.CommandContext(ctx, "go", "test", "-json", "-count=1", "-run", "Example", w.pkg)
+	test.Dir, test.Stdout, test.Stderr = w.root, stdout, stderr
+	testErr := test.Run()
+
+	summary, err := parseTestEvents(stdout)
+	switch {
+	case err != nil:
+		fmt.Fprintf(w.out, "  tests   ERROR %v\n", err)
+	case testErr != nil && len(summary.failed) == 0:
+		// Didn't build, or timed out, there's no test to blame
+		fmt.Fprintf(w.out, "  tests   FAIL  %v\n", testErr)
+		indent(w.out, stderr.String())
+		return
+	default:
+		summary.print(w.out)
+	}
+
+	args := []string{"run", w.command}
+	if w.example != 0 {
+		args = append(args, "--example", strconv.Itoa(w.example))
+	}
+	stdout.Reset()
+	stderr.Reset()
+	solve := exec.CommandContext(ctx, "go", args...)
+	solve.Dir, solve.Stdout, solve.Stderr = w.root, stdout, stderr
+	start := time.Now()
+	err = solve.Run()
+	elapsed := time.Since(start)
+
+	indent(w.out, stdout.String())
+	if err != nil {
+		fmt.Fprintf(w.out, "  solve   FAIL  %v\n", err)
+		indent(w.out, stderr.String())
+		return
+	}
+	fmt.Fprintf(w.out, "  solve   done in %s, including the build\n", formatDuration(elapsed))
+}
+
+// indent writes text with every line indented to sit under the summary
+func indent(w io.Writer, text string) {
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if line != "" {
+			fmt.Fprintf(w, "          %s\n", line)
+		}
+	}
+}
+
+// runWatch implements the 'watch' command
+func runWatch(args []string) error {
+	flags := flag.NewFlagSet("watch", flag.ContinueOnError)
+	year := yearFlag(flags)
+	interval := flags.Duration("interval", 200*time.Millisecond, "How often to look for changes")
+	debounce := flags.Duration("debounce", 300*time.Millisecond, "How long things have to be quiet before rerunning")
+	timeout := flags.Duration("timeout", time.Minute, "Give up on a round of testing and solving after this long")
+	example := flags.Int("example", 0, "Solve the day's nth stored example instead of the real input")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if err := resolveYear(year); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) != 1 {
+		return fmt.Errorf("watch expects a single arg 'day', got: %v", args)
+	}
+	day, err := utils.ParseDay(args[0])
+	if err != nil {
+		return err
+	}
+	if *interval <= 0 || *debounce < 0 || *timeout <= 0 {
+		return fmt.Errorf("interval and timeout should be positive and debounce not negative")
+	}
+
+	root := utils.Root()
+	dir := utils.Dir(*year, day)
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("day %d hasn't been set up yet, run 'go run ./scripts new %d' first: %w", day, day, err)
+	}
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(dir)
+	w := &watcher{
+		out:     os.Stdout,
+		root:    root,
+		pkg:     "./" + filepath.ToSlash(rel),
+		command: "./" + filepath.ToSlash(filepath.Join(rel, "cmd", name)),
+		timeout: *timeout,
+		example: *example,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+
+	fmt.Fprintf(w.out, "watching %s, Ctrl-C to stop\n", rel)
+	w.run(ctx)
+	return watchDir(ctx, dir, ticker.C, *debounce, func(changed []string) {
+		names := make([]string, 0, len(changed))
+		for _, path := range changed {
+			if name, err := filepath.Rel(dir, path); err == nil {
+				names = append(names, name)
+			}
+		}
+		fmt.Fprintf(w.out, "\n[%s] %s changed\n", time.Now().Format("15:04:05"), strings.Join(names, ", "))
+		w.run(ctx)
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestChangedFiles(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	code := filepath.Join(dir, "day06.go")
+	input := filepath.Join(dir, "day06.txt")
+	is.NoErr(os.WriteFile(code, []byte("package day06\n"), 0o644))
+	is.NoErr(os.WriteFile(input, []byte("1\n"), 0o644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "notes.md"), []byte("ignored\n"), 0o644))
+
+	before, err := snapshot(dir)
+	is.NoErr(err)
+	is.Equal(len(before), 2) // Only Go and text files
+
+	is.NoErr(os.WriteFile(code, []byte("package day06\n\nfunc part1() {}\n"), 0o644))
+	is.NoErr(os.Remove(input))
+	example := filepath.Join(dir, "testdata", "example1.txt")
+	is.NoErr(os.MkdirAll(filepath.Dir(example), 0o755))
+	is.NoErr(os.WriteFile(example, []byte("2\n"), 0o644))
+
+	after, err := snapshot(dir)
+	is.NoErr(err)
+	is.Equal(changedFiles(before, after), []string{code, input, example})
+	is.Equal(len(changedFiles(after, after)), 0)
+}
+
+func TestWatchDirDebounce(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "day06.go")
+	is.NoErr(os.WriteFile(path, []byte("package day06\n"), 0o644))
+
+	var (
+		mu    sync.Mutex
+		calls [][]string
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	done := make(chan error)
+	go func() {
+		done <- watchDir(ctx, dir, ticks, 100*time.Millisecond, func(changed []string) {
+			mu.Lock()
+			defer mu.Unlock()
+			calls = append(calls, changed)
+		})
+	}()
+
+	// The channel's unbuffered so each tick is taken only once the last one's
+	// been dealt with, and the first one means the starting snapshot is done
+	start := time.Now()
+	tick := func(after time.Duration) { ticks <- start.Add(after) }
+	tick(0)
+
+	// A burst of saves is one change, sizes differ so it doesn't hang on mtime resolution
+	for i := 1; i <= 3; i++ {
+		is.NoErr(os.WriteFile(path, []byte(strings.Repeat("/", i)+"\npackage day06\n"), 0o644))
+		tick(time.Duration(i) * 10 * time.Millisecond)
+	}
+	tick(120 * time.Millisecond) // Not quiet for long enough yet
+	tick(130 * time.Millisecond)
+	tick(500 * time.Millisecond) // Nothing left to report
+	cancel()
+	is.NoErr(<-done)
+
+	mu.Lock()
+	defer mu.Unlock()
+	is.Equal(calls, [][]string{{path}})
+}
+
+func TestParseTestEvents(t *testing.T) {
+	is := is.New(t)
+	events := `{"Action":"run","Test":"TestExamplePart1"}
+{"Action":"output","Test":"TestExamplePart1","Output":"=== RUN   TestExamplePart1\n"}
+{"Action":"pass","Test":"TestExamplePart1"}
+{"Action":"run","Test":"TestExamplePart2"}
+{"Action":"output","Test":"TestExamplePart2","Output":"    day06_test.go:20: got 3, wanted 4\n"}
+{"Action":"output","Test":"TestExamplePart2","Output":"--- FAIL: TestExamplePart2 (0.00s)\n"}
+{"Action":"fail","Test":"TestExamplePart2"}
+{"Action":"fail"}
+`
+	summary, err := parseTestEvents(strings.NewReader(events))
+	is.NoErr(err)
+	is.Equal(summary.passed, []string{"TestExamplePart1"})
+	is.Equal(summary.failed, []string{"TestExamplePart2"})
+
+	buf := &bytes.Buffer{}
+	summary.print(buf)
+	is.Equal(buf.String(), "  tests   FAIL  1 passed, 1 failed\n"+
+		"              day06_test.go:20: got 3, wanted 4\n")
+
+	buf.Reset()
+	testSummary{passed: []string{"TestExamplePart1"}}.print(buf)
+	is.Equal(buf.String(), "  tests   PASS  1 passed\n")
+
+	// A freshly scaffolded day's example test skips until it's filled in
+	summary, err = parseTestEvents(strings.NewReader(`{"Action":"skip","Test":"TestExamplePart1"}` + "\n"))
+	is.NoErr(err)
+	buf.Reset()
+	summary.print(buf)
+	is.Equal(buf.String(), "  tests   SKIP  1 skipped\n")
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+const (
+	WORKERS  = 4               // Days scaffolded at once by default
+	INTERVAL = 1 * time.Second // Default minimum gap between requests to the site in bulk
+)
+
+// parseDays parses the argument to 'new', a single day, a range like 6-25, or 'all'
+func parseDays(arg string) ([]int, error) {
+	if arg == "all" {
+		return dayRange(1, 25), nil
+	}
+
+	bounds := strings.SplitN(arg, "-", 2)
+	if len(bounds) == 1 {
+		day, err := utils.ParseDay(arg)
+		if err != nil {
+			return nil, err
+		}
+		return []int{day}, nil
+	}
+
+	start, err := utils.ParseDay(bounds[0])
+	if err != nil {
+		return nil, fmt.Errorf("bad start of range %q: %w", arg, err)
+	}
+	end, err := utils.ParseDay(bounds[1])
+	if err != nil {
+		return nil, fmt.Errorf("bad end of range %q: %w", arg, err)
+	}
+	if start > end {
+		return nil, fmt.Errorf("range %q runs backwards", arg)
+	}
+
+	return dayRange(start, end), nil
+}
+
+// dayRange returns the days from start to end inclusive
+func dayRange(start, end int) []int {
+	days := make([]int, 0, end-start+1)
+	for day := start; day <= end; day++ {
+		days = append(days, day)
+	}
+	return days
+}
+
+// limiter spaces out requests to the site so however many workers there are,
+// we never hit it more often than once per interval
+type limiter struct {
+	next     time.Time
+	mu       sync.Mutex
+	interval time.Duration
+}
+
+func newLimiter(interval time.Duration) *limiter {
+	return &limiter{interval: interval}
+}
+
+// wait blocks until it's this caller's turn, a nil limiter never blocks
+func (l *limiter) wait() {
+	if l == nil {
+		return
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	turn := l.next
+	if turn.Before(now) {
+		turn = now
+	}
+	l.next = turn.Add(l.interval)
+	l.mu.Unlock()
+
+	time.Sleep(time.Until(turn))
+}
+
+// bulkResult is what happened to one day in a bulk scaffold
+type bulkResult struct {
+	err     error
+	plan    *dayPlan
+	day     int
+	skipped bool
+}
+
+// bulkSummary is what happened to every day in a bulk scaffold, in day order
+type bulkSummary struct {
+	results []bulkResult
+	created []int
+	skipped []int
+	failed  []bulkResult
+	dryRun  bool
+}
+
+// scaffoldDays scaffolds days using a pool of workers, a failure on one day
+// is recorded in the summary and doesn't stop any of the others
+func scaffoldDays(e *env, days []int, opts newOptions, workers int) *bulkSummary {
+	if workers > len(days) {
+		workers = len(days)
+	}
+
+	results := make([]bulkResult, len(days))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = scaffoldOne(e, days[i], opts)
+			}
+		}()
+	}
+
+	for i := range days {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary := &bulkSummary{results: results, dryRun: opts.dryRun}
+	for _, result := range results {
+		switch {
+		case result.err != nil:
+			summary.failed = append(summary.failed, result)
+		case result.skipped:
+			summary.skipped = append(summary.skipped, result.day)
+		default:
+			summary.created = append(summary.created, result.day)
+		}
+	}
+	sort.Ints(summary.created)
+	sort.Ints(summary.skipped)
+
+	return summary
+}
+
+// scaffoldOne is a single worker's go at a day, days already there are skipped
+// rather than failed unless we've been asked to fill them in
+func scaffoldOne(e *env, day int, opts newOptions) bulkResult {
+	if !opts.mode.force && !opts.mode.onlyInput && exists(filepath.Join(e.root, dayDir(e.year, day))) {
+		return bulkResult{day: day, skipped: true}
+	}
+
+	plan, err := scaffoldDay(e, day, opts)
+	return bulkResult{day: day, plan: plan, err: err}
+}
+
+// print writes the summary, preceded in a dry run by the plan for each day
+func (s *bulkSummary) print(w io.Writer) {
+	verb := "created"
+	if s.dryRun {
+		verb = "planned"
+		for _, result := range s.results {
+			if result.plan != nil {
+				result.plan.print(w)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%s  %s\n", verb, joinDays(s.created))
+	fmt.Fprintf(w, "skipped  %s\n", joinDays(s.skipped))
+	fmt.Fprintf(w, "failed   %d\n", len(s.failed))
+	for _, result := range s.failed {
+		fmt.Fprintf(w, "  day %d: %v\n", result.day, result.err)
+	}
+}
+
+// joinDays formats a list of days for the summary
+func joinDays(days []int) string {
+	if len(days) == 0 {
+		return "-"
+	}
+	strs := make([]string, 0, len(days))
+	for _, day := range days {
+		strs = append(strs, strconv.Itoa(day))
+	}
+	return strings.Join(strs, ", ")
+}
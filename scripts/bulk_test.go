@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestParseDays(t *testing.T) {
+	tests := []struct {
+		name    string
+		arg     string
+		want    []int
+		wantErr bool
+	}{
+		{name: "single", arg: "6", want: []int{6}},
+		{name: "range", arg: "6-9", want: []int{6, 7, 8, 9}},
+		{name: "range of one", arg: "25-25", want: []int{25}},
+		{name: "all", arg: "all", want: dayRange(1, 25)},
+		{name: "backwards", arg: "9-6", wantErr: true},
+		{name: "too far", arg: "20-26", wantErr: true},
+		{name: "zero", arg: "0", wantErr: true},
+		{name: "junk", arg: "six", wantErr: true},
+		{name: "open range", arg: "6-", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			got, err := parseDays(tt.arg)
+			is.Equal(err != nil, tt.wantErr)
+			if !tt.wantErr {
+				is.Equal(got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	is := is.New(t)
+	l := newLimiter(20 * time.Millisecond)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		l.wait()
+	}
+	is.True(time.Since(start) >= 60*time.Millisecond) // First goes straight away, then 3 gaps
+
+	var none *limiter
+	none.wait() // Must not block or panic
+}
+
+// testEnv returns an env for a project in a temporary directory talking to site
+func testEnv(t *testing.T, site *fakeSite) *env {
+	t.Helper()
+	return &env{
+		root:  t.TempDir(),
+		cache: NewCache(t.TempDir()),
+		tokens: tokenSources{
+			flag:      testSession,
+			lookupEnv: func(string) (string, bool) { return "", false },
+		},
+		config: fetchConfig{BaseURL: site.URL, Timeout: time.Second},
+		year:   2020,
+	}
+}
+
+func TestScaffoldDays(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "1721\n"
+	site.inputs[3] = "..##\n"
+	site.inputs[4] = "byr:1937\n"
+	site.puzzles[3] = string(page(partOneHTML))
+	// Day 2 hasn't unlocked
+
+	e := testEnv(t, site)
+	e.limiter = newLimiter(time.Millisecond)
+	is.NoErr(os.MkdirAll(filepath.Join(e.root, "2020", "day01"), 0o755))
+
+	opts := newOptions{templates: t.TempDir()}
+	summary := scaffoldDays(e, []int{1, 2, 3, 4}, opts, 2)
+
+	is.Equal(summary.created, []int{3, 4})
+	is.Equal(summary.skipped, []int{1})
+	is.Equal(len(summary.failed), 1)
+	is.Equal(summary.failed[0].day, 2)
+	is.True(errors.Is(summary.failed[0].err, ErrNotUnlocked))
+
+	is.True(exists(filepath.Join(e.root, "2020", "day03", "day03_test.go")))
+	is.True(exists(filepath.Join(e.root, "2020", "day04", "day04.txt")))
+	is.True(!exists(filepath.Join(e.root, "2020", "day02")))
+
+	// Only day 3 had a description to take the example from
+	example, err := os.ReadFile(filepath.Join(e.root, "2020", "day03", "testdata", "example1.txt"))
+	is.NoErr(err)
+	is.Equal(string(example), "1721\n979\n366\n")
+	is.True(!exists(filepath.Join(e.root, "2020", "day04", "testdata")))
+
+	buf := &bytes.Buffer{}
+	summary.print(buf)
+	is.True(strings.HasPrefix(buf.String(), "created  3, 4\nskipped  1\nfailed   1\n  day 2: "))
+}
+
+func TestScaffoldDaysDryRun(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	e := testEnv(t, site)
+
+	summary := scaffoldDays(e, []int{5, 6}, newOptions{dryRun: true}, 4)
+	is.Equal(summary.created, []int{5, 6})
+	is.Equal(len(site.requests), 0) // Dry runs don't fetch anything
+
+	buf := &bytes.Buffer{}
+	summary.print(buf)
+	is.True(strings.Contains(buf.String(), "planned  5, 6\n"))
+
+	entries, err := os.ReadDir(e.root)
+	is.NoErr(err)
+	is.Equal(len(entries), 0)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// The kinds of thing we keep in the cache
+const (
+	kindInput       = "input.txt"
+	kindPuzzle      = "puzzle.html"
+	kindSubmissions = "submissions.json"
+	kindCalendar    = "calendar.html"
+)
+
+var (
+	// ErrNotCached is returned when the requested item is not in the cache
+	ErrNotCached = errors.New("not in cache")
+
+	// ErrCacheCorrupt is returned when a cached item no longer matches its recorded hash
+	ErrCacheCorrupt = errors.New("cached item does not match its recorded hash")
+)
+
+// Meta is the metadata stored alongside every cached item
+type Meta struct {
+	FetchedAt time.Time `json:"fetched_at"`
+	Kind      string    `json:"kind"`
+	SHA256    string    `json:"sha256"`
+	Year      int       `json:"year"`
+	Day       int       `json:"day"`
+	Size      int       `json:"size"`
+}
+
+// Cache is an on-disk store of things fetched from adventofcode.com, keyed
+// by year and day so we never have to download the same thing twice
+//
+// Items live at <dir>/<year>/dayNN/<kind> with their metadata next to them
+// in <kind>.json, items that belong to a whole year (day 0) live directly
+// under <dir>/<year>
+type Cache struct {
+	dir string
+}
+
+// NewCache returns a Cache rooted at dir, the directory is created lazily on first write
+func NewCache(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+// defaultCacheDir returns $AOC_CACHE_DIR if set, otherwise an 'aoc' directory
+// under the user's cache directory
+func defaultCacheDir() (string, error) {
+	if dir, ok := os.LookupEnv("AOC_CACHE_DIR"); ok && dir != "" {
+		return dir, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("could not determine user cache directory, set AOC_CACHE_DIR: %w", err)
+	}
+	return filepath.Join(base, "aoc"), nil
+}
+
+// path returns the filepath of a cached item
+func (c *Cache) path(year, day int, kind string) string {
+	if day == 0 {
+		return filepath.Join(c.dir, fmt.Sprint(year), kind)
+	}
+	return filepath.Join(c.dir, fmt.Sprint(year), fmt.Sprintf("day%02d", day), kind)
+}
+
+// Get returns a cached item and its metadata
+//
+// If the item isn't there it returns ErrNotCached, if it is there but its
+// contents no longer match the hash recorded when it was stored it returns ErrCacheCorrupt
+func (c *Cache) Get(year, day int, kind string) ([]byte, *Meta, error) {
+	path := c.path(year, day, kind)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, fmt.Errorf("%d day %d %s: %w", year, day, kind, ErrNotCached)
+		}
+		return nil, nil, err
+	}
+
+	raw, err := os.ReadFile(path + ".json")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			// Data without metadata means an interrupted write, can't trust it
+			return nil, nil, fmt.Errorf("%d day %d %s: missing metadata: %w", year, day, kind, ErrCacheCorrupt)
+		}
+		return nil, nil, err
+	}
+
+	meta := &Meta{}
+	if err := json.Unmarshal(raw, meta); err != nil {
+		return nil, nil, fmt.Errorf("%d day %d %s: bad metadata: %w", year, day, kind, ErrCacheCorrupt)
+	}
+
+	if hash(data) != meta.SHA256 {
+		return nil, nil, fmt.Errorf("%d day %d %s: %w", year, day, kind, ErrCacheCorrupt)
+	}
+
+	return data, meta, nil
+}
+
+// Put stores data in the cache, replacing anything already there, and returns its metadata
+func (c *Cache) Put(year, day int, kind string, data []byte) (*Meta, error) {
+	path := c.path(year, day, kind)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	meta := &Meta{
+		Year:      year,
+		Day:       day,
+		Kind:      kind,
+		FetchedAt: time.Now().UTC(),
+		SHA256:    hash(data),
+		Size:      len(data),
+	}
+
+	raw, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	// Data first, so a crash in between leaves an item without metadata
+	// which Get treats as corrupt rather than a stale hash over new data
+	if err := writeFileAtomic(path, data); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path+".json", raw); err != nil {
+		return nil, err
+	}
+
+	return meta, nil
+}
+
+// writeFileAtomic writes data to a temporary file next to path and renames
+// it into place so readers never see a partially written file
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	// CreateTemp makes files only we can read
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// hash returns the hex encoded sha256 of data
+func hash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+	"github.com/matryer/is"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+
+	_, _, err := cache.Get(2020, 1, kindInput)
+	is.True(errors.Is(err, ErrNotCached))
+
+	want := []byte("1721\n979\n366\n")
+	meta, err := cache.Put(2020, 1, kindInput, want)
+	is.NoErr(err)
+	is.Equal(meta.Size, len(want))
+	is.Equal(meta.SHA256, hash(want))
+
+	got, gotMeta, err := cache.Get(2020, 1, kindInput)
+	is.NoErr(err)
+	is.Equal(got, want)
+	is.Equal(gotMeta.SHA256, meta.SHA256)
+	is.True(gotMeta.FetchedAt.Equal(meta.FetchedAt))
+}
+
+func TestCacheCorrupt(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+
+	_, err := cache.Put(2020, 3, kindInput, []byte("..##.......\n"))
+	is.NoErr(err)
+
+	// Someone edits the cached input by hand
+	err = os.WriteFile(cache.path(2020, 3, kindInput), []byte("#.##...\n"), 0o644)
+	is.NoErr(err)
+
+	_, _, err = cache.Get(2020, 3, kindInput)
+	is.True(errors.Is(err, ErrCacheCorrupt))
+}
+
+func TestLoadInputOffline(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+	connect := func() (*Fetcher, error) {
+		t.Fatal("offline mode went to the network")
+		return nil, nil
+	}
+
+	_, err := loadInput(cache, connect, 2020, 5, true)
+	is.True(errors.Is(err, ErrNotCached))
+
+	want := []byte("BFFFBBFRRR\n")
+	_, err = cache.Put(utils.YEAR, 5, kindInput, want)
+	is.NoErr(err)
+
+	got, err := loadInput(cache, connect, 2020, 5, true)
+	is.NoErr(err)
+	is.Equal(got, want)
+}
+
+func TestLoadInputFetchesOnce(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+	site := newFakeSite(t)
+	site.inputs[5] = "BFFFBBFRRR\n"
+	connect := func() (*Fetcher, error) { return site.fetcher(t), nil }
+
+	for i := 0; i < 3; i++ {
+		got, err := loadInput(cache, connect, 2020, 5, false)
+		is.NoErr(err)
+		is.Equal(string(got), site.inputs[5])
+	}
+
+	is.Equal(len(site.requests), 1) // Only the first should hit the network
+}
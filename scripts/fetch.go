@@ -0,0 +1,293 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	USERAGENT  = "github.com/FollowTheProcess/advent_of_code_2020/scripts by github.com/FollowTheProcess"
+	RETRIES    = 3                      // Extra attempts at a GET after a transient failure
+	BACKOFF    = 500 * time.Millisecond // Wait before the first retry, doubling each time after
+	MAXBACKOFF = 30 * time.Second       // Longest we'll wait between attempts
+)
+
+// Errors for the things the site tells us instead of giving us what we asked for
+var (
+	ErrUnauthorized = errors.New("not logged in, check AOC_SESSION is set and current")
+	ErrNotUnlocked  = errors.New("puzzle has not unlocked yet")
+	ErrRateLimited  = errors.New("rate limited by the server")
+	ErrNotFound     = errors.New("not found")
+	ErrEmptyInput   = errors.New("puzzle input was empty")
+)
+
+// errorTexts are the bits of the site's error pages we know how to spot,
+// some of them come back with a 200 so the status alone isn't enough
+var errorTexts = []struct {
+	err  error
+	text string
+}{
+	{err: ErrUnauthorized, text: "Puzzle inputs differ by user.  Please log in to get your puzzle input."},
+	{err: ErrUnauthorized, text: "To play, please identify yourself"},
+	{err: ErrNotUnlocked, text: "Please don't repeatedly request this endpoint before it unlocks!"},
+}
+
+// Fetcher talks to adventofcode.com, or anything that looks like it
+type Fetcher struct {
+	Client  *http.Client  // The client used for all requests
+	BaseURL string        // Scheme and host with no trailing slash e.g. "https://adventofcode.com"
+	Session string        // Value of the 'session' cookie
+	Retries int           // Extra attempts at a GET after a transient failure
+	Backoff time.Duration // Wait before the first retry, doubling each time after
+	Limiter *limiter      // Spaces out requests, may be shared between Fetchers, nil for no limit
+}
+
+// NewFetcher returns a Fetcher talking to baseURL with its own client that
+// gives up on any single request after timeout
+func NewFetcher(baseURL, session string, timeout time.Duration) (*Fetcher, error) {
+	jar, err := cookiejar.New(nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := &http.Client{
+		Jar:     jar,
+		Timeout: timeout,
+	}
+
+	f := &Fetcher{
+		Client:  client,
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Session: session,
+		Retries: RETRIES,
+		Backoff: BACKOFF,
+	}
+
+	return f, nil
+}
+
+// fetchConfig is how to reach the site, resolved from flags then environment then defaults
+type fetchConfig struct {
+	BaseURL string
+	Timeout time.Duration
+}
+
+// resolveFetchConfig fills in anything not set by a flag from $AOC_BASE_URL and
+// $AOC_TIMEOUT, falling back to URL and TIMEOUT
+func resolveFetchConfig(baseURL string, timeout time.Duration) (fetchConfig, error) {
+	if baseURL == "" {
+		baseURL = os.Getenv("AOC_BASE_URL")
+	}
+	if baseURL == "" {
+		baseURL = URL
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return fetchConfig{}, fmt.Errorf("invalid base URL %q: %w", baseURL, err)
+	}
+
+	if timeout == 0 {
+		if env := os.Getenv("AOC_TIMEOUT"); env != "" {
+			t, err := time.ParseDuration(env)
+			if err != nil {
+				return fetchConfig{}, fmt.Errorf("AOC_TIMEOUT is not a valid duration: %w", err)
+			}
+			timeout = t
+		}
+	}
+	if timeout <= 0 {
+		timeout = TIMEOUT
+	}
+
+	return fetchConfig{BaseURL: baseURL, Timeout: timeout}, nil
+}
+
+// Input gets a day's puzzle input
+func (f *Fetcher) Input(year, day int) ([]byte, error) {
+	data, err := f.do(http.MethodGet, fmt.Sprintf("/%d/day/%d/input", year, day), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, fmt.Errorf("%d day %d: %w", year, day, ErrEmptyInput)
+	}
+	return data, nil
+}
+
+// Puzzle gets the raw HTML of a day's puzzle page
+func (f *Fetcher) Puzzle(year, day int) ([]byte, error) {
+	return f.do(http.MethodGet, fmt.Sprintf("/%d/day/%d", year, day), nil)
+}
+
+// Answer posts an answer for one part of a day's puzzle and returns the raw HTML response
+func (f *Fetcher) Answer(year, day, part int, answer string) ([]byte, error) {
+	form := url.Values{}
+	form.Set("level", fmt.Sprint(part))
+	form.Set("answer", answer)
+	return f.do(http.MethodPost, fmt.Sprintf("/%d/day/%d/answer", year, day), form)
+}
+
+// transientError is a failure worth trying again after a while
+type transientError struct {
+	err  error
+	wait time.Duration // How long the server asked us to wait, if it did
+}
+
+func (t *transientError) Error() string {
+	return t.err.Error()
+}
+
+func (t *transientError) Unwrap() error {
+	return t.err
+}
+
+// do makes an authenticated request to path, sending form as the body if
+// it's not nil, and returns the body of a verified successful response
+//
+// GETs that fail for a transient reason (network errors, server errors, rate
+// limits) are retried with exponential backoff and jitter, POSTs never are
+// as we can't know whether the first one landed
+func (f *Fetcher) do(method, path string, form url.Values) ([]byte, error) {
+	attempts := 1
+	if method == http.MethodGet && f.Retries > 0 {
+		attempts += f.Retries
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		var data []byte
+		data, err = f.once(method, path, form)
+
+		var transient *transientError
+		if !errors.As(err, &transient) {
+			return data, err
+		}
+		err = transient.err
+
+		if attempt < attempts-1 {
+			time.Sleep(f.backoff(attempt, transient.wait))
+		}
+	}
+
+	return nil, fmt.Errorf("%s %s: giving up after %d attempts: %w", method, path, attempts, err)
+}
+
+// backoff returns how long to wait before the retry following attempt (0 indexed),
+// the exponential backoff plus up to the same again in jitter, or what the
+// server asked for if that's longer
+func (f *Fetcher) backoff(attempt int, asked time.Duration) time.Duration {
+	wait := f.Backoff << attempt
+	if wait <= 0 || wait > MAXBACKOFF {
+		wait = MAXBACKOFF
+	}
+	if wait > 0 {
+		wait += time.Duration(rand.Int63n(int64(wait)))
+	}
+	if asked > wait {
+		wait = asked
+	}
+	if wait > MAXBACKOFF {
+		wait = MAXBACKOFF
+	}
+	return wait
+}
+
+// once makes a single attempt at a request
+func (f *Fetcher) once(method, path string, form url.Values) ([]byte, error) {
+	var body io.Reader
+	if form != nil {
+		body = strings.NewReader(form.Encode())
+	}
+
+	req, err := http.NewRequest(method, f.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	req.Header.Set("User-Agent", USERAGENT)
+	f.Limiter.wait()
+	req.AddCookie(&http.Cookie{
+		Name:  "session",
+		Value: f.Session,
+	})
+
+	resp, err := f.Client.Do(req)
+	if err != nil {
+		var netErr net.Error
+		if errors.As(err, &netErr) {
+			return nil, &transientError{err: err}
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		// Connection dropped part way through
+		return nil, &transientError{err: err}
+	}
+
+	if err := checkResponse(resp, data); err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+
+	return data, nil
+}
+
+// checkResponse returns an error if resp isn't a genuine success, recognising
+// the site's error pages whatever status they come back with
+func checkResponse(resp *http.Response, data []byte) error {
+	for _, known := range errorTexts {
+		if bytes.Contains(data, []byte(known.text)) {
+			return known.err
+		}
+	}
+
+	switch code := resp.StatusCode; {
+	case code == http.StatusOK:
+		return nil
+	case code >= 300 && code < 400:
+		// We only see redirects when we've asked not to follow them, and the
+		// site only redirects to send people to the login page
+		return fmt.Errorf("redirected to %s: %w", resp.Header.Get("Location"), ErrUnauthorized)
+	case code == http.StatusBadRequest, code == http.StatusUnauthorized, code == http.StatusForbidden:
+		return fmt.Errorf("%s: %w", resp.Status, ErrUnauthorized)
+	case code == http.StatusNotFound:
+		return fmt.Errorf("%s: %w", resp.Status, ErrNotFound)
+	case code == http.StatusTooManyRequests:
+		return &transientError{
+			err:  fmt.Errorf("%s: %w", resp.Status, ErrRateLimited),
+			wait: retryAfter(resp.Header.Get("Retry-After")),
+		}
+	case code >= 500:
+		return &transientError{err: fmt.Errorf("server error: %s", resp.Status)}
+	default:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+}
+
+// retryAfter parses a Retry-After header in either of its forms, returning 0 if it can't
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+const testSession = "53616c7465645f5f"
+
+// fakeSite is a stand in for adventofcode.com serving the input, puzzle and answer endpoints
+type fakeSite struct {
+	*httptest.Server
+	inputs   map[int]string    // Day -> puzzle input
+	puzzles  map[int]string    // Day -> puzzle page HTML
+	answers  map[[2]int]string // (day, part) -> the right answer
+	solved   map[[2]int]bool   // (day, part) -> answered correctly already
+	delay    time.Duration     // How long to stall before responding
+	limited  bool              // Whether to reject answers for being too soon
+	failures int               // How many requests to fail with a 503 before behaving
+	mu       sync.Mutex
+	requests []string // Every request path seen, in order
+}
+
+// newFakeSite starts a fakeSite for 2020 that is shut down when the test finishes
+func newFakeSite(t *testing.T) *fakeSite {
+	t.Helper()
+	site := &fakeSite{
+		inputs:  make(map[int]string),
+		puzzles: make(map[int]string),
+		answers: make(map[[2]int]string),
+		solved:  make(map[[2]int]bool),
+	}
+
+	mux := http.NewServeMux()
+	for day := 1; day <= 25; day++ {
+		page := fmt.Sprintf("/2020/day/%d", day)
+		mux.Handle(page, site.route(day, site.handlePuzzle))
+		mux.Handle(page+"/input", site.route(day, site.handleInput))
+		mux.Handle(page+"/answer", site.route(day, site.handleAnswer))
+	}
+	mux.HandleFunc("/settings", site.handleSettings)
+	site.Server = httptest.NewServer(mux)
+	t.Cleanup(site.Close)
+
+	return site
+}
+
+// route wraps the handler for one of day's pages with what every page does
+// first: recording the request, checking the User-Agent, failing and stalling
+func (s *fakeSite) route(day int, handler func(w http.ResponseWriter, r *http.Request, day int)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+		delay := s.delay
+		fail := s.failures > 0
+		if fail {
+			s.failures--
+		}
+		s.mu.Unlock()
+
+		if r.Header.Get("User-Agent") != USERAGENT {
+			http.Error(w, "Please identify your tool in the User-Agent", http.StatusForbidden)
+			return
+		}
+
+		if fail {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+
+		if delay > 0 {
+			select {
+			case <-time.After(delay):
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		handler(w, r, day)
+	}
+}
+
+// loggedIn reports whether r carries the test session cookie
+func loggedIn(r *http.Request) bool {
+	cookie, err := r.Cookie("session")
+	return err == nil && cookie.Value == testSession
+}
+
+func (s *fakeSite) handleInput(w http.ResponseWriter, r *http.Request, day int) {
+	if !loggedIn(r) {
+		http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+		return
+	}
+	input, ok := s.inputs[day]
+	if !ok {
+		http.Error(w, "Please don't repeatedly request this endpoint before it unlocks! The calendar countdown is synchronized with the server time; the link will be enabled on the calendar the instant this puzzle becomes available.", http.StatusNotFound)
+		return
+	}
+	fmt.Fprint(w, input)
+}
+
+func (s *fakeSite) handlePuzzle(w http.ResponseWriter, r *http.Request, day int) {
+	puzzle, ok := s.puzzles[day]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	fmt.Fprint(w, puzzle)
+}
+
+func (s *fakeSite) handleAnswer(w http.ResponseWriter, r *http.Request, day int) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !loggedIn(r) {
+		http.Error(w, "Please log in.", http.StatusBadRequest)
+		return
+	}
+	part, err := strconv.Atoi(r.PostFormValue("level"))
+	if err != nil {
+		http.Error(w, "Bad level", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	msg := s.verdict([2]int{day, part}, r.PostFormValue("answer"))
+	s.mu.Unlock()
+	fmt.Fprintf(w, "<html><body><main>\n<article><p>%s</p></article>\n</main></body></html>", msg)
+}
+
+// verdict is what the site says about answer for key, s.mu must be held
+func (s *fakeSite) verdict(key [2]int, answer string) string {
+	switch {
+	case s.limited:
+		return "You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 1m 4s left to wait. <a href=\"/2020/day/1\">[Return to Day 1]</a>"
+	case s.solved[key]:
+		return "You don't seem to be solving the right level.  Did you already complete it? <a href=\"/2020/day/1\">[Return to Day 1]</a>"
+	case answer == s.answers[key]:
+		s.solved[key] = true
+		return "That's the right answer!  You are <span class=\"day-success\">one gold star</span> closer to saving your vacation."
+	}
+
+	hint := ""
+	got, errGot := strconv.Atoi(answer)
+	want, errWant := strconv.Atoi(s.answers[key])
+	if errGot == nil && errWant == nil {
+		if got > want {
+			hint = "; your answer is too high"
+		} else {
+			hint = "; your answer is too low"
+		}
+	}
+	return "That's not the right answer" + hint + ".  If you're stuck, make sure you're using the full input data.  Please wait one minute before trying again."
+}
+
+func (s *fakeSite) handleSettings(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	s.mu.Unlock()
+
+	cookie, err := r.Cookie("session")
+	if err != nil || cookie.Value != testSession {
+		http.Redirect(w, r, "/2020/auth/login", http.StatusFound)
+		return
+	}
+	fmt.Fprint(w, `<html><body><main><form method="post" action="/settings">What would you like to be called?</form></main></body></html>`)
+}
+
+// fetcher returns a Fetcher logged in to the site with a short timeout
+func (s *fakeSite) fetcher(t *testing.T) *Fetcher {
+	t.Helper()
+	f, err := NewFetcher(s.URL, testSession, time.Second)
+	if err != nil {
+		t.Fatalf("NewFetcher: %v", err)
+	}
+	f.Backoff = time.Millisecond
+	return f
+}
+
+func TestFetcherInput(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "1721\n979\n366\n"
+
+	got, err := site.fetcher(t).Input(2020, 1)
+	is.NoErr(err)
+	is.Equal(string(got), "1721\n979\n366\n")
+	is.Equal(site.requests, []string{"GET /2020/day/1/input"})
+}
+
+func TestFetcherInputNoSession(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "1721\n979\n366\n"
+
+	f := site.fetcher(t)
+	f.Session = "not-my-session"
+
+	got, err := f.Input(2020, 1)
+	is.True(errors.Is(err, ErrUnauthorized))
+	is.Equal(got, nil)              // The "please log in" page must not come back as input
+	is.Equal(len(site.requests), 1) // Not worth retrying
+}
+
+func TestFetcherInputNotUnlocked(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+
+	got, err := site.fetcher(t).Input(2020, 25)
+	is.True(errors.Is(err, ErrNotUnlocked))
+	is.Equal(got, nil)
+}
+
+func TestFetcherRetries(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "1721\n"
+	site.failures = 2
+
+	got, err := site.fetcher(t).Input(2020, 1)
+	is.NoErr(err)
+	is.Equal(string(got), "1721\n")
+	is.Equal(len(site.requests), 3)
+}
+
+func TestFetcherGivesUp(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "1721\n"
+	site.failures = 10
+
+	f := site.fetcher(t)
+	f.Retries = 2
+
+	_, err := f.Input(2020, 1)
+	is.True(err != nil)
+	is.Equal(len(site.requests), 3)
+}
+
+func TestFetcherNoRetryPost(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.answers[[2]int{1, 1}] = "514579"
+	site.failures = 1
+
+	_, err := site.fetcher(t).Answer(2020, 1, 1, "514579")
+	is.True(err != nil)
+	is.Equal(len(site.requests), 1) // Might have counted, so must not resend
+}
+
+func TestFetcherEmptyInput(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "\n"
+
+	_, err := site.fetcher(t).Input(2020, 1)
+	is.True(errors.Is(err, ErrEmptyInput))
+}
+
+func TestCheckResponse(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		status    int
+		want      error
+		transient bool
+	}{
+		{name: "ok", status: http.StatusOK, body: "1721\n979\n"},
+		{name: "log in page", status: http.StatusBadRequest, body: "Puzzle inputs differ by user.  Please log in to get your puzzle input.\n", want: ErrUnauthorized},
+		{name: "log in page with 200", status: http.StatusOK, body: "Puzzle inputs differ by user.  Please log in to get your puzzle input.\n", want: ErrUnauthorized},
+		{name: "forbidden", status: http.StatusForbidden, body: "", want: ErrUnauthorized},
+		{name: "not unlocked", status: http.StatusNotFound, body: "Please don't repeatedly request this endpoint before it unlocks! The calendar countdown is synchronized with the server time.", want: ErrNotUnlocked},
+		{name: "plain 404", status: http.StatusNotFound, body: "404 Not Found", want: ErrNotFound},
+		{name: "rate limited", status: http.StatusTooManyRequests, want: ErrRateLimited, transient: true},
+		{name: "server error", status: http.StatusBadGateway, transient: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			resp := &http.Response{StatusCode: tt.status, Status: http.StatusText(tt.status), Header: http.Header{}}
+
+			err := checkResponse(resp, []byte(tt.body))
+			if tt.want == nil && !tt.transient {
+				is.NoErr(err)
+				return
+			}
+			is.True(err != nil)
+			if tt.want != nil {
+				is.True(errors.Is(err, tt.want))
+			}
+			var transient *transientError
+			is.Equal(errors.As(err, &transient), tt.transient)
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	is := is.New(t)
+	f := &Fetcher{Backoff: 100 * time.Millisecond}
+
+	for attempt := 0; attempt < 4; attempt++ {
+		base := f.Backoff << attempt
+		got := f.backoff(attempt, 0)
+		is.True(got >= base && got < 2*base) // exponential plus jitter
+	}
+
+	is.Equal(f.backoff(0, 10*time.Second), 10*time.Second) // Server knows best
+	is.Equal(f.backoff(0, time.Hour), MAXBACKOFF)          // Within reason
+	is.Equal(f.backoff(20, 0), MAXBACKOFF)
+}
+
+func TestFetcherTimeout(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.inputs[1] = "1721\n"
+	site.delay = time.Second
+
+	f, err := NewFetcher(site.URL, testSession, 50*time.Millisecond)
+	is.NoErr(err)
+	f.Retries = 0
+
+	start := time.Now()
+	_, err = f.Input(2020, 1)
+	is.True(err != nil)
+	is.True(time.Since(start) < site.delay)
+}
+
+func TestFetcherPuzzle(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.puzzles[3] = `<article class="day-desc"><h2>--- Day 3: Toboggan Trajectory ---</h2></article>`
+
+	got, err := site.fetcher(t).Puzzle(2020, 3)
+	is.NoErr(err)
+	is.Equal(string(got), site.puzzles[3])
+}
+
+func TestFetcherAnswer(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+	site.answers[[2]int{1, 1}] = "514579"
+
+	f := site.fetcher(t)
+
+	got, err := f.Answer(2020, 1, 1, "42")
+	is.NoErr(err)
+	is.True(strings.Contains(string(got), "That's not the right answer"))
+
+	got, err = f.Answer(2020, 1, 1, "514579")
+	is.NoErr(err)
+	is.True(strings.Contains(string(got), "That's the right answer"))
+}
+
+func TestResolveFetchConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		flagURL     string
+		flagTimeout time.Duration
+		env         map[string]string
+		want        fetchConfig
+		wantErr     bool
+	}{
+		{
+			name: "defaults",
+			want: fetchConfig{BaseURL: URL, Timeout: TIMEOUT},
+		},
+		{
+			name: "env",
+			env:  map[string]string{"AOC_BASE_URL": "http://localhost:8080", "AOC_TIMEOUT": "3s"},
+			want: fetchConfig{BaseURL: "http://localhost:8080", Timeout: 3 * time.Second},
+		},
+		{
+			name:        "flags beat env",
+			flagURL:     "http://127.0.0.1:9000",
+			flagTimeout: time.Minute,
+			env:         map[string]string{"AOC_BASE_URL": "http://localhost:8080", "AOC_TIMEOUT": "3s"},
+			want:        fetchConfig{BaseURL: "http://127.0.0.1:9000", Timeout: time.Minute},
+		},
+		{
+			name:    "bad timeout",
+			env:     map[string]string{"AOC_TIMEOUT": "soon"},
+			wantErr: true,
+		},
+		{
+			name:    "bad url",
+			flagURL: "adventofcode",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			for _, key := range []string{"AOC_BASE_URL", "AOC_TIMEOUT"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			got, err := resolveFetchConfig(tt.flagURL, tt.flagTimeout)
+			is.Equal(err != nil, tt.wantErr)
+			if !tt.wantErr {
+				is.Equal(got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,360 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// LEADERBOARDTTL is how long the site asks us to leave between leaderboard requests
+const LEADERBOARDTTL = 15 * time.Minute
+
+// ErrBadLeaderboardID is returned for a leaderboard ID that isn't a number, it
+// ends up in a cache path and a URL so nothing else is let through
+var ErrBadLeaderboardID = errors.New("leaderboard ID should be the number in its URL")
+
+// unixTime is a timestamp from the leaderboard API which, depending on the
+// year, is either a number or a string holding a number
+type unixTime struct {
+	time.Time
+}
+
+func (u *unixTime) UnmarshalJSON(data []byte) error {
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" || raw == "null" || raw == "0" {
+		u.Time = time.Time{}
+		return nil
+	}
+	seconds, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return fmt.Errorf("bad timestamp %s: %w", data, err)
+	}
+	u.Time = time.Unix(seconds, 0).UTC()
+	return nil
+}
+
+// flexInt is an integer from the leaderboard API which, like unixTime, may come as a string
+type flexInt int
+
+func (f *flexInt) UnmarshalJSON(data []byte) error {
+	n, err := strconv.Atoi(strings.Trim(string(data), `"`))
+	if err != nil {
+		return fmt.Errorf("bad integer %s: %w", data, err)
+	}
+	*f = flexInt(n)
+	return nil
+}
+
+// rawLeaderboard is the private leaderboard JSON as the site sends it
+type rawLeaderboard struct {
+	Members map[string]struct {
+		Days map[string]map[string]struct {
+			GetStarTS unixTime `json:"get_star_ts"`
+		} `json:"completion_day_level"`
+		Name       *string  `json:"name"`
+		LastStarTS unixTime `json:"last_star_ts"`
+		ID         flexInt  `json:"id"`
+		Stars      flexInt  `json:"stars"`
+		LocalScore flexInt  `json:"local_score"`
+	} `json:"members"`
+	Event string `json:"event"`
+}
+
+// DayStars is when a member got each star for one day
+type DayStars struct {
+	Part1 *time.Time     `json:"part1,omitempty"`
+	Part2 *time.Time     `json:"part2,omitempty"`
+	Delta *time.Duration `json:"delta_ns,omitempty"` // Time from part 1 to part 2
+	Day   int            `json:"day"`
+}
+
+// Standing is one member's place on the leaderboard
+type Standing struct {
+	LastStar time.Time  `json:"last_star"`
+	Name     string     `json:"name"`
+	Days     []DayStars `json:"days"`
+	Rank     int        `json:"rank"`
+	ID       int        `json:"id"`
+	Score    int        `json:"score"`
+	Stars    int        `json:"stars"`
+}
+
+// parseLeaderboard turns the site's leaderboard JSON into standings, best first
+func parseLeaderboard(data []byte) ([]Standing, error) {
+	raw := rawLeaderboard{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("leaderboard is not valid JSON, check the session can see it: %w", err)
+	}
+
+	standings := make([]Standing, 0, len(raw.Members))
+	for _, member := range raw.Members {
+		s := Standing{
+			ID:       int(member.ID),
+			Score:    int(member.LocalScore),
+			Stars:    int(member.Stars),
+			LastStar: member.LastStarTS.Time,
+		}
+		if member.Name != nil && *member.Name != "" {
+			s.Name = *member.Name
+		} else {
+			s.Name = fmt.Sprintf("(anonymous user #%d)", member.ID)
+		}
+
+		for dayStr, levels := range member.Days {
+			day, err := strconv.Atoi(dayStr)
+			if err != nil {
+				return nil, fmt.Errorf("bad day %q for member %d", dayStr, member.ID)
+			}
+			d := DayStars{Day: day}
+			if one, ok := levels["1"]; ok && !one.GetStarTS.IsZero() {
+				t := one.GetStarTS.Time
+				d.Part1 = &t
+			}
+			if two, ok := levels["2"]; ok && !two.GetStarTS.IsZero() {
+				t := two.GetStarTS.Time
+				d.Part2 = &t
+			}
+			if d.Part1 != nil && d.Part2 != nil {
+				delta := d.Part2.Sub(*d.Part1)
+				d.Delta = &delta
+			}
+			s.Days = append(s.Days, d)
+		}
+		sort.Slice(s.Days, func(i, j int) bool { return s.Days[i].Day < s.Days[j].Day })
+
+		standings = append(standings, s)
+	}
+
+	// Score, then stars, then whoever got there first
+	sort.Slice(standings, func(i, j int) bool {
+		a, b := standings[i], standings[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.Stars != b.Stars {
+			return a.Stars > b.Stars
+		}
+		if !a.LastStar.Equal(b.LastStar) {
+			return a.LastStar.Before(b.LastStar)
+		}
+		return a.ID < b.ID
+	})
+
+	for i := range standings {
+		standings[i].Rank = i + 1
+		// Ties share a rank like they do on the site
+		if i > 0 && standings[i].Score == standings[i-1].Score {
+			standings[i].Rank = standings[i-1].Rank
+		}
+	}
+
+	return standings, nil
+}
+
+// stars returns the glyph for how many stars a member has on day
+func (s Standing) stars(day int) string {
+	for _, d := range s.Days {
+		if d.Day != day {
+			continue
+		}
+		switch {
+		case d.Part2 != nil:
+			return "*"
+		case d.Part1 != nil:
+			return "+"
+		}
+	}
+	return "."
+}
+
+// renderStandings writes the leaderboard as a table with a column per day,
+// '*' is both stars, '+' just the first and '.' none
+func renderStandings(w io.Writer, standings []Standing) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 1, ' ', tabwriter.AlignRight)
+
+	header := []string{"#", "Score", "Stars"}
+	for day := 1; day <= 25; day++ {
+		header = append(header, strconv.Itoa(day%10))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t\tName\t")
+
+	for _, s := range standings {
+		row := []string{strconv.Itoa(s.Rank) + ")", strconv.Itoa(s.Score), strconv.Itoa(s.Stars)}
+		for day := 1; day <= 25; day++ {
+			row = append(row, s.stars(day))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t\t"+s.Name+"\t")
+	}
+
+	return tw.Flush()
+}
+
+// renderDayStars writes when every member got each star on day and how long part 2 took them
+func renderDayStars(w io.Writer, standings []Standing, day int, loc *time.Location) error {
+	type row struct {
+		name  string
+		stars DayStars
+	}
+	var rows []row
+	for _, s := range standings {
+		for _, d := range s.Days {
+			if d.Day == day {
+				rows = append(rows, row{name: s.Name, stars: d})
+			}
+		}
+	}
+
+	// Fastest to both stars first, then fastest to one
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].stars, rows[j].stars
+		if (a.Part2 == nil) != (b.Part2 == nil) {
+			return a.Part2 != nil
+		}
+		if a.Part2 != nil {
+			return a.Part2.Before(*b.Part2)
+		}
+		return a.Part1.Before(*b.Part1)
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Name\tPart 1\tPart 2\tDelta\n")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", r.name, stamp(r.stars.Part1, loc), stamp(r.stars.Part2, loc), delta(r.stars.Delta))
+	}
+	return tw.Flush()
+}
+
+// stamp formats an optional star time
+func stamp(t *time.Time, loc *time.Location) string {
+	if t == nil {
+		return "-"
+	}
+	return t.In(loc).Format("Jan 02 15:04:05")
+}
+
+// delta formats an optional part 1 to part 2 delta
+func delta(d *time.Duration) string {
+	if d == nil {
+		return "-"
+	}
+	return d.Round(time.Second).String()
+}
+
+// Leaderboard gets the raw JSON of a private leaderboard
+func (f *Fetcher) Leaderboard(year int, id string) ([]byte, error) {
+	return f.do(http.MethodGet, fmt.Sprintf("/%d/leaderboard/private/view/%s.json", year, id), nil)
+}
+
+// loadLeaderboard returns a private leaderboard's JSON, from the cache if it's
+// younger than LEADERBOARDTTL at now, otherwise from the site
+//
+// If the site can't be reached, a stale cached copy is better than nothing
+func loadLeaderboard(cache *Cache, connect func() (*Fetcher, error), year int, id string, now time.Time) ([]byte, error) {
+	if !isDigits(id) {
+		return nil, fmt.Errorf("%w, got: %q", ErrBadLeaderboardID, id)
+	}
+	kind := "leaderboard-" + id + ".json"
+
+	cached, meta, cacheErr := cache.Get(year, 0, kind)
+	if cacheErr == nil && now.Sub(meta.FetchedAt) < LEADERBOARDTTL {
+		return cached, nil
+	}
+
+	data, err := fetchLeaderboard(connect, year, id)
+	if err != nil {
+		if cacheErr == nil {
+			fmt.Fprintf(os.Stderr, "warning: using leaderboard from %s: %v\n", meta.FetchedAt.Local().Format(time.Kitchen), err)
+			return cached, nil
+		}
+		return nil, err
+	}
+
+	if _, err := cache.Put(year, 0, kind, data); err != nil {
+		return nil, fmt.Errorf("could not cache leaderboard: %w", err)
+	}
+	return data, nil
+}
+
+// isDigits reports whether s is a non empty string of ASCII digits
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// fetchLeaderboard gets a leaderboard from the site and checks it's really the JSON
+func fetchLeaderboard(connect func() (*Fetcher, error), year int, id string) ([]byte, error) {
+	fetcher, err := connect()
+	if err != nil {
+		return nil, err
+	}
+	data, err := fetcher.Leaderboard(year, id)
+	if err != nil {
+		return nil, err
+	}
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("leaderboard %s: %w", id, ErrUnauthorized)
+	}
+	return data, nil
+}
+
+// runLeaderboard implements the 'leaderboard' command
+func runLeaderboard(args []string) error {
+	var common commonFlags
+	flags := flag.NewFlagSet("leaderboard", flag.ContinueOnError)
+	common.register(flags)
+	id := flags.String("id", os.Getenv("AOC_LEADERBOARD"), "Private leaderboard ID, the number in its URL (default $AOC_LEADERBOARD)")
+	day := flags.Int("day", 0, "Show each member's star times and part 1 to 2 delta for this day")
+	asJSON := flags.Bool("json", false, "Print the standings as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *id == "" {
+		return errors.New("leaderboard needs an --id or $AOC_LEADERBOARD")
+	}
+	if *day < 0 || *day > 25 {
+		return fmt.Errorf("day should be between 1 and 25, got: %d", *day)
+	}
+
+	e, err := common.env()
+	if err != nil {
+		return err
+	}
+
+	data, err := loadLeaderboard(e.cache, e.connect, e.year, *id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	standings, err := parseLeaderboard(data)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *asJSON:
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(standings)
+	case *day != 0:
+		return renderDayStars(os.Stdout, standings, *day, time.Local)
+	default:
+		return renderStandings(os.Stdout, standings)
+	}
+}
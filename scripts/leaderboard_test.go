@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// readFixture returns a recorded response from testdata
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("could not read fixture: %v", err)
+	}
+	return data
+}
+
+func TestParseLeaderboard(t *testing.T) {
+	is := is.New(t)
+
+	standings, err := parseLeaderboard(readFixture(t, "leaderboard.json"))
+	is.NoErr(err)
+	is.Equal(len(standings), 3)
+
+	// Tied on score, more stars wins but the rank is shared
+	is.Equal(standings[0].Name, "Tom Fleet")
+	is.Equal(standings[0].Rank, 1)
+	is.Equal(standings[1].Name, "Ada")
+	is.Equal(standings[1].Rank, 1)
+	is.Equal(standings[2].Name, "(anonymous user #303)")
+	is.Equal(standings[2].Rank, 3)
+
+	tom := standings[0]
+	is.Equal(len(tom.Days), 3)
+	is.Equal(tom.Days[0].Day, 1)
+	is.Equal(*tom.Days[0].Delta, 346*time.Second)
+	is.Equal(tom.Days[0].Part1.Unix(), int64(1606800735))
+
+	ada := standings[1]
+	is.Equal(ada.Days[2].Part2, nil)
+	is.Equal(ada.Days[2].Delta, nil)
+}
+
+func TestParseLeaderboardNotJSON(t *testing.T) {
+	is := is.New(t)
+	_, err := parseLeaderboard([]byte("<html>To play, please identify yourself</html>"))
+	is.True(err != nil)
+}
+
+func TestRenderStandings(t *testing.T) {
+	is := is.New(t)
+
+	standings, err := parseLeaderboard(readFixture(t, "leaderboard.json"))
+	is.NoErr(err)
+
+	buf := &bytes.Buffer{}
+	is.NoErr(renderStandings(buf, standings))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	is.Equal(len(lines), 4)
+	is.True(strings.Contains(lines[1], "1)    34     6 * * * . . ."))
+	is.True(strings.HasSuffix(lines[1], "Tom Fleet"))
+	is.True(strings.Contains(lines[2], "1)    34     5 * * + . . ."))
+}
+
+func TestRenderDayStars(t *testing.T) {
+	is := is.New(t)
+
+	standings, err := parseLeaderboard(readFixture(t, "leaderboard.json"))
+	is.NoErr(err)
+
+	buf := &bytes.Buffer{}
+	is.NoErr(renderDayStars(buf, standings, 3, time.UTC))
+
+	want := "Name       Part 1           Part 2           Delta\n" +
+		"Tom Fleet  Dec 03 05:40:59  Dec 04 07:31:53  25h50m54s\n" +
+		"Ada        Dec 03 05:56:40  -                -\n"
+	is.Equal(buf.String(), want)
+}
+
+func TestLoadLeaderboardCaches(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+	site := newFakeSite(t)
+	fixture := readFixture(t, "leaderboard.json")
+	site.Config.Handler.(*http.ServeMux).HandleFunc("/2020/leaderboard/private/view/101.json", func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requests = append(site.requests, r.Method+" "+r.URL.Path)
+		site.mu.Unlock()
+		w.Write(fixture)
+	})
+	connect := func() (*Fetcher, error) { return site.fetcher(t), nil }
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		got, err := loadLeaderboard(cache, connect, 2020, "101", now)
+		is.NoErr(err)
+		is.Equal(got, fixture)
+	}
+	is.Equal(len(site.requests), 1) // Within the 15 minutes, the rest came from the cache
+
+	_, err := loadLeaderboard(cache, connect, 2020, "101", now.Add(LEADERBOARDTTL+time.Minute))
+	is.NoErr(err)
+	is.Equal(len(site.requests), 2)
+
+	// Site goes down, stale is better than nothing
+	broken := func() (*Fetcher, error) { return nil, errors.New("offline") }
+	got, err := loadLeaderboard(cache, broken, 2020, "101", now.Add(time.Hour))
+	is.NoErr(err)
+	is.Equal(got, fixture)
+
+	_, err = loadLeaderboard(cache, broken, 2020, "999", now)
+	is.True(err != nil)
+}
+
+func TestLoadLeaderboardBadID(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+	cache := NewCache(filepath.Join(dir, "cache"))
+	connect := func() (*Fetcher, error) { return nil, errors.New("shouldn't get as far as the site") }
+
+	for _, id := range []string{"../x", "../../2021/day01/input.txt", "12a", "", "-1", "１２"} {
+		_, err := loadLeaderboard(cache, connect, 2020, id, time.Now())
+		is.True(errors.Is(err, ErrBadLeaderboardID)) // ID should have been rejected
+	}
+
+	entries, err := os.ReadDir(dir)
+	is.NoErr(err)
+	is.Equal(len(entries), 0) // Something was written for a bad ID
+}
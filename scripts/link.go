@@ -0,0 +1,63 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// MODULE is the import path of the repo, as in go.mod
+const MODULE = "github.com/FollowTheProcess/advent_of_code_2020"
+
+// daysFile is where the runner imports every day so they register themselves, relative to the root
+var daysFile = filepath.Join("cmd", "aoc", "days.go")
+
+// linkDays rewrites the runner's list of day imports to cover every day under root,
+// reporting whether anything changed
+func linkDays(root string) (bool, error) {
+	matches, err := filepath.Glob(filepath.Join(root, "[0-9][0-9][0-9][0-9]", "day[0-9][0-9]", "day[0-9][0-9].go"))
+	if err != nil {
+		return false, err
+	}
+
+	var imports []string
+	for _, match := range matches {
+		dir, err := filepath.Rel(root, filepath.Dir(match))
+		if err != nil {
+			return false, err
+		}
+		imports = append(imports, MODULE+"/"+filepath.ToSlash(dir))
+	}
+	sort.Strings(imports)
+
+	src, err := renderDaysFile(imports)
+	if err != nil {
+		return false, err
+	}
+
+	path := filepath.Join(root, daysFile)
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, src) {
+		return false, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return false, err
+	}
+	return true, writeFileAtomic(path, src)
+}
+
+// renderDaysFile returns the source of the runner's days.go importing each of imports
+func renderDaysFile(imports []string) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("// Code generated by 'go run ./scripts new'. DO NOT EDIT.\n\n")
+	buf.WriteString("package main\n\n")
+	buf.WriteString("// Every day's solution, imported so it registers itself with the runner\n")
+	buf.WriteString("import (\n")
+	for _, imp := range imports {
+		fmt.Fprintf(&buf, "\t_ %q\n", imp)
+	}
+	buf.WriteString(")\n")
+	return format.Source(buf.Bytes())
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestLinkDays(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+
+	for _, dir := range []string{filepath.Join("2021", "day02"), filepath.Join("2020", "day01"), filepath.Join("2020", "day03")} {
+		is.NoErr(os.MkdirAll(filepath.Join(root, dir), 0o755))
+		name := filepath.Base(dir)
+		is.NoErr(os.WriteFile(filepath.Join(root, dir, name+".go"), []byte("package "+name+"\n"), 0o644))
+	}
+	// Not a day, just a directory that's been made
+	is.NoErr(os.MkdirAll(filepath.Join(root, "2020", "day04"), 0o755))
+
+	changed, err := linkDays(root)
+	is.NoErr(err)
+	is.True(changed)
+
+	got, err := os.ReadFile(filepath.Join(root, daysFile))
+	is.NoErr(err)
+	is.True(strings.HasPrefix(string(got), "// Code generated"))
+	is.True(strings.Contains(string(got), "import (\n"+
+		"\t_ \""+MODULE+"/2020/day01\"\n"+
+		"\t_ \""+MODULE+"/2020/day03\"\n"+
+		"\t_ \""+MODULE+"/2021/day02\"\n"+
+		")\n"))
+
+	changed, err = linkDays(root)
+	is.NoErr(err)
+	is.True(!changed) // Nothing new the second time
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+const (
+	TIMEOUT = 10 * time.Second
+	URL     = "https://adventofcode.com"
+)
+
+const usage = `Usage: go run ./scripts <command> [flags] [args]
+
+Commands:
+  new          Scaffold a new day from its input and puzzle description
+  read         Read a day's puzzle description from the cache, no network needed
+  submit       Submit an answer for one part of a day's puzzle
+  leaderboard  Show a private leaderboard's standings
+  status       Show progress on each day, from the site's stars and what's done locally
+  token        Check which session token is in use and that it works ('token check')
+
+Run 'go run ./scripts <command> -h' for a command's flags.`
+
+func main() {
+	args := os.Args[1:]
+	if err := run(args); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(args []string) error {
+	if len(args) < 1 {
+		return errors.New(usage)
+	}
+
+	switch args[0] {
+	case "new":
+		return runNew(args[1:])
+	case "read":
+		return runRead(args[1:])
+	case "submit":
+		return runSubmit(args[1:])
+	case "leaderboard":
+		return runLeaderboard(args[1:])
+	case "status":
+		return runStatus(args[1:])
+	case "token":
+		return runToken(args[1:])
+	default:
+		return fmt.Errorf("unknown command %q\n\n%s", args[0], usage)
+	}
+}
+
+// commonFlags are the flags shared by every command that talks to the site
+type commonFlags struct {
+	session  string
+	cacheDir string
+	baseURL  string
+	timeout  time.Duration
+	year     int
+}
+
+// register adds the common flags to a command's flag set
+func (c *commonFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&c.session, "session", "", "Session token, overriding $AOC_SESSION, .env and the user config dir")
+	flags.StringVar(&c.cacheDir, "cache-dir", "", "Directory for the local cache (default $AOC_CACHE_DIR or the user cache dir)")
+	flags.StringVar(&c.baseURL, "base-url", "", "Base URL of the Advent of Code site (default $AOC_BASE_URL or "+URL+")")
+	flags.DurationVar(&c.timeout, "timeout", 0, "Timeout for each HTTP request (default $AOC_TIMEOUT or "+TIMEOUT.String()+")")
+	flags.IntVar(&c.year, "year", 0, "Year of the event, days live under <root>/<year>/dayNN (default $AOC_YEAR or "+strconv.Itoa(utils.YEAR)+")")
+}
+
+// env is everything a command needs to find its way around
+type env struct {
+	cache   *Cache
+	limiter *limiter // Shared by every Fetcher from connect, nil for no limit
+	root    string   // Absolute path to the project root
+	tokens  tokenSources
+	config  fetchConfig
+	year    int
+}
+
+// env resolves the common flags into an env
+func (c *commonFlags) env() (*env, error) {
+	_, here, _, ok := runtime.Caller(0)
+	if !ok {
+		return nil, errors.New("could not get root directory")
+	}
+	root := filepath.Join(here, "../..")
+
+	cacheDir := c.cacheDir
+	if cacheDir == "" {
+		var err error
+		cacheDir, err = defaultCacheDir()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	config, err := resolveFetchConfig(c.baseURL, c.timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	year, err := utils.ResolveYear(c.year)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &env{
+		root:   root,
+		cache:  NewCache(cacheDir),
+		tokens: defaultTokenSources(c.session, root),
+		config: config,
+		year:   year,
+	}
+
+	return e, nil
+}
+
+// connect returns a Fetcher logged in to the site, it's only called once
+// we actually have to go to the network so offline use needs no session
+func (e *env) connect() (*Fetcher, error) {
+	token, err := e.tokens.resolve()
+	if err != nil {
+		return nil, err
+	}
+	fetcher, err := NewFetcher(e.config.BaseURL, token.Value, e.config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	fetcher.Limiter = e.limiter
+	return fetcher, nil
+}
@@ -2,147 +2,280 @@ package main
 
 import (
 	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"io/fs"
-	"log"
-	"net/http"
-	"net/http/cookiejar"
 	"os"
 	"path/filepath"
-	"runtime"
-	"strconv"
-	"time"
-
-	"github.com/joho/godotenv"
 )
 
-const (
-	TIMEOUT = 10 * time.Second
-	URL     = "https://adventofcode.com/2020"
-)
+// newOptions are the flags for the 'new' command that shape how each day is scaffolded
+type newOptions struct {
+	templates string
+	mode      scaffoldMode
+	offline   bool
+	dryRun    bool
+}
 
-func main() {
-	args := os.Args[1:]
-	if err := run(args); err != nil {
-		log.Fatal(err)
+// runNew implements the 'new' command, scaffolding a day's directory
+//
+// It takes a single day, a range like 6-25, or 'all'
+func runNew(args []string) error {
+	var (
+		common commonFlags
+		opts   newOptions
+	)
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	common.register(flags)
+	flags.BoolVar(&opts.offline, "offline", false, "Scaffold purely from the local cache, never touching the network")
+	flags.StringVar(&opts.templates, "templates", "", "Directory of templates overriding the defaults (default $AOC_TEMPLATES or the user config dir)")
+	flags.BoolVar(&opts.dryRun, "dry-run", false, "Print the files that would be written without writing anything")
+	flags.BoolVar(&opts.mode.force, "force", false, "For an existing day, create any missing files and refresh the input, never touching existing code")
+	flags.BoolVar(&opts.mode.onlyInput, "only-input", false, "Only write the day's input, creating or refreshing it")
+	refresh := flags.Bool("refresh", false, "Add the part two description to an existing day's doc comment once it unlocks")
+	workers := flags.Int("workers", WORKERS, "How many days to scaffold at once when given a range")
+	interval := flags.Duration("interval", INTERVAL, "Minimum time between requests to the site when given a range")
+	wait := flags.Bool("wait", false, "Wait for the day's puzzle to unlock, then scaffold it straight away")
+	window := flags.Duration("wait-window", WAITWINDOW, "With --wait, refuse to wait for a puzzle that unlocks further away than this")
+	jitter := flags.Duration("jitter", WAITJITTER, "With --wait, the most to wait after unlocking so we don't all arrive at once")
+	if err := flags.Parse(args); err != nil {
+		return err
 	}
-}
+	args = flags.Args()
 
-func run(args []string) error {
-	if len(args) < 1 {
-		return fmt.Errorf("new expects a single arg 'day', got: %v", args)
+	if len(args) != 1 {
+		return fmt.Errorf("new expects a single arg 'day', a range like '6-25' or 'all', got: %v", args)
 	}
-	// Arg should be a day integer
-	day, err := strconv.Atoi(args[0])
+	days, err := parseDays(args[0])
 	if err != nil {
-		return fmt.Errorf("argument to new should be a valid integer, got: %s", args[0])
-	}
-
-	_, here, _, ok := runtime.Caller(0)
-	if !ok {
-		return errors.New("could not get root directory")
+		return err
 	}
-	root := filepath.Join(here, "../..")
 
-	err = godotenv.Load(filepath.Join(root, ".env"))
+	e, err := common.env()
 	if err != nil {
 		return err
 	}
 
-	session, ok := os.LookupEnv("AOC_SESSION")
-	if !ok {
-		return errors.New("missing AOC_SESSION in .env")
+	if *refresh {
+		if len(days) != 1 {
+			return errors.New("--refresh works on a single day")
+		}
+		return refreshDay(e.cache, e.connect, e.root, e.year, days[0])
 	}
 
-	dayStr := fmt.Sprintf("day%02d", day)
-	if exists(filepath.Join(root, dayStr)) {
-		return fmt.Errorf("%s already exists", filepath.Join(root, dayStr))
+	if *wait {
+		if len(days) != 1 {
+			return errors.New("--wait works on a single day")
+		}
+		w := newWaiter(os.Stderr, *window, *jitter)
+		if err := w.wait(e.year, days[0]); err != nil {
+			return err
+		}
+		return w.untilUnlocked(func() error {
+			plan, err := scaffoldDay(e, days[0], opts)
+			if err != nil {
+				return err
+			}
+			plan.print(os.Stdout)
+			return link(e, opts)
+		})
 	}
 
-	data, err := getInput(day, session)
-	if err != nil {
-		return err
+	if len(days) == 1 {
+		plan, err := scaffoldDay(e, days[0], opts)
+		if err != nil {
+			return err
+		}
+		plan.print(os.Stdout)
+		return link(e, opts)
 	}
 
-	err = makeDay(root, day, data)
-	if err != nil {
-		return err
+	if *workers < 1 {
+		return fmt.Errorf("--workers must be at least 1, got: %d", *workers)
 	}
+	e.limiter = newLimiter(*interval)
 
+	summary := scaffoldDays(e, days, opts, *workers)
+	summary.print(os.Stdout)
+	if err := link(e, opts); err != nil {
+		return err
+	}
+	if len(summary.failed) > 0 {
+		return fmt.Errorf("%d of %d days failed", len(summary.failed), len(days))
+	}
 	return nil
 }
 
-// getInput gets a day's puzzle input and returns it as a byte slice
-func getInput(day int, session string) ([]byte, error) {
-	jar, err := cookiejar.New(nil)
+// scaffoldDay scaffolds a single day and returns what it did, or with opts.dryRun, would do
+func scaffoldDay(e *env, day int, opts newOptions) (*dayPlan, error) {
+	plan, err := planDay(e.root, e.year, day, opts.mode)
 	if err != nil {
 		return nil, err
 	}
 
-	url := fmt.Sprintf("%s/day/%d/input", URL, day)
+	if opts.dryRun {
+		return plan, nil
+	}
+
+	contents := make(map[role][]byte)
+
+	if plan.needs(roleInput) {
+		contents[roleInput], err = loadInput(e.cache, e.connect, e.year, day, opts.offline)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if plan.needs(roleCode) || plan.needs(roleTest) || plan.needs(roleCommand) || plan.needs(roleExample) {
+		templates := opts.templates
+		if templates == "" {
+			templates = defaultTemplateDir()
+		}
+		tmpl, err := loadTemplates(templates)
+		if err != nil {
+			return nil, err
+		}
+
+		d := dayData{
+			Year: e.year,
+			Day:  day,
+			Name: dayName(day),
+		}
+
+		// The description is nice to have, not worth failing the whole day over
+		page, err := loadPuzzle(e.cache, e.connect, e.year, day, opts.offline)
+		if err == nil {
+			d.Header, err = docComment(page)
+			d.Example = extractExample(page)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: scaffolding day %d without a description: %v\n", day, err)
+		}
+
+		// Not every puzzle has an example worth keeping
+		if d.Example == "" {
+			plan.skip(roleExample)
+		} else {
+			contents[roleExample] = []byte(d.Example)
+		}
 
-	client := &http.Client{
-		Jar:     jar,
-		Timeout: TIMEOUT,
+		sources, err := renderDay(tmpl, d)
+		if err != nil {
+			return nil, err
+		}
+		for r, src := range sources {
+			contents[r] = src
+		}
 	}
 
-	sessionCookie := &http.Cookie{
-		Name:  "session",
-		Value: session,
+	if err := plan.apply(e.root, contents); err != nil {
+		return nil, err
 	}
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	return plan, nil
+}
+
+// link adds any new days to the runner's imports, unless it's a dry run
+func link(e *env, opts newOptions) error {
+	if opts.dryRun {
+		return nil
+	}
+	changed, err := linkDays(e.root)
 	if err != nil {
+		return fmt.Errorf("could not add new days to the runner: %w", err)
+	}
+	if changed {
+		fmt.Printf("%-9s %s\n", "update", daysFile)
+	}
+	return nil
+}
+
+// loadInput returns a day's puzzle input, preferring the local cache and only
+// going to adventofcode.com (and caching the result) when it's missing
+//
+// connect is only called if we need to go to the network
+func loadInput(cache *Cache, connect func() (*Fetcher, error), year, day int, offline bool) ([]byte, error) {
+	return load(cache, connect, year, day, kindInput, (*Fetcher).Input, offline)
+}
+
+// loadPuzzle is loadInput for the puzzle page
+func loadPuzzle(cache *Cache, connect func() (*Fetcher, error), year, day int, offline bool) ([]byte, error) {
+	return load(cache, connect, year, day, kindPuzzle, (*Fetcher).Puzzle, offline)
+}
+
+// load returns an item from the cache, or if it's not there, fetches it with fetch and caches it
+func load(
+	cache *Cache,
+	connect func() (*Fetcher, error),
+	year, day int,
+	kind string,
+	fetch func(f *Fetcher, year, day int) ([]byte, error),
+	offline bool,
+) ([]byte, error) {
+	data, _, err := cache.Get(year, day, kind)
+	switch {
+	case err == nil:
+		return data, nil
+	case errors.Is(err, ErrNotCached), errors.Is(err, ErrCacheCorrupt):
+		if offline {
+			return nil, fmt.Errorf("cannot scaffold day %d with --offline: %w", day, err)
+		}
+	default:
 		return nil, err
 	}
-	req.AddCookie(sessionCookie)
 
-	resp, err := client.Do(req)
+	fetcher, err := connect()
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	data, err := io.ReadAll(resp.Body)
+	data, err = fetch(fetcher, year, day)
 	if err != nil {
 		return nil, err
 	}
 
+	if _, err := cache.Put(year, day, kind, data); err != nil {
+		return nil, fmt.Errorf("could not cache %s for day %d: %w", kind, day, err)
+	}
+
 	return data, nil
 }
 
-// makeDay creates the directory and files for a days advent of code and saves the input
-func makeDay(root string, day int, data []byte) error {
-	dayPath := filepath.Join(root, fmt.Sprintf("day%02d", day))
-
-	err := os.Mkdir(dayPath, os.ModeSticky|os.ModePerm)
+// refreshDay re-fetches a day's puzzle page and adds the part two description
+// to the doc comment of its dayNN.go, leaving the code alone
+func refreshDay(cache *Cache, connect func() (*Fetcher, error), root string, year, day int) error {
+	dayGo := filepath.Join(root, dayDir(year, day), dayName(day)+".go")
+	src, err := os.ReadFile(dayGo)
 	if err != nil {
 		return err
 	}
 
-	dayGo := filepath.Join(dayPath, fmt.Sprintf("day%02d.go", day))
-	dayTest := filepath.Join(dayPath, fmt.Sprintf("day%02d_test.go", day))
-	dayInput := filepath.Join(dayPath, fmt.Sprintf("day%02d.txt", day))
-
-	err = os.WriteFile(dayGo, []byte("package main\n"), os.ModeSticky|os.ModePerm)
+	// Always go to the site, the cached page is from before part two unlocked
+	fetcher, err := connect()
 	if err != nil {
 		return err
 	}
-
-	err = os.WriteFile(dayTest, []byte("package main\n"), os.ModeSticky|os.ModePerm)
+	page, err := fetcher.Puzzle(year, day)
 	if err != nil {
 		return err
 	}
+	if _, err := cache.Put(year, day, kindPuzzle, page); err != nil {
+		return fmt.Errorf("could not cache puzzle for day %d: %w", day, err)
+	}
 
-	err = os.WriteFile(dayInput, data, os.ModeSticky|os.ModePerm)
+	updated, changed, err := refreshDocComment(src, page)
 	if err != nil {
 		return err
 	}
+	if !changed {
+		fmt.Printf("Nothing new for day %d\n", day)
+		return nil
+	}
 
-	return nil
+	return writeFileAtomic(dayGo, updated)
 }
 
+// exists reports whether there is anything at path
 func exists(path string) bool {
 	_, err := os.Stat(path)
 	if err != nil {
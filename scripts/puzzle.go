@@ -0,0 +1,436 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// WIDTH is the column puzzle prose is wrapped at
+const WIDTH = 80
+
+// ErrNoDescription is returned when a page has no puzzle description on it
+var ErrNoDescription = errors.New(`no <article class="day-desc"> found in puzzle page`)
+
+// articleRegex matches each part's description on a puzzle page, they never nest
+var articleRegex = regexp.MustCompile(`(?s)<article class="day-desc">.*?</article>`)
+
+// extractArticles returns the raw HTML of each part's description on a puzzle page,
+// there's one before part 1 is solved and two after
+func extractArticles(page []byte) []string {
+	matches := articleRegex.FindAll(page, -1)
+	articles := make([]string, 0, len(matches))
+	for _, match := range matches {
+		articles = append(articles, string(match))
+	}
+	return articles
+}
+
+// blockKind is the type of a block of rendered text
+type blockKind int
+
+const (
+	heading blockKind = iota
+	paragraph
+	code
+	item
+)
+
+type block struct {
+	text strings.Builder
+	kind blockKind
+}
+
+// style is how rendered text marks up the parts of a description that aren't plain prose
+type style struct {
+	emphasis, emphasisEnd string
+	code, codeEnd         string
+	heading, headingEnd   string
+	block, blockEnd       string // Around each line of a code block
+	bullet, hang          string // Start of the first and following lines of a list item
+	emphasisInCode        bool   // Whether <em> inside <code> is marked up too
+
+	// A list and a code block next to each other in a doc comment are both
+	// indented so gofmt merges them, with this set those lists are written flat
+	flatListsByCode bool
+}
+
+// plainStyle is for doc comments, code blocks and lists are laid out the way gofmt formats them
+var plainStyle = style{
+	emphasis:        "*",
+	emphasisEnd:     "*",
+	code:            "`",
+	codeEnd:         "`",
+	block:           "\t",
+	bullet:          "  - ",
+	hang:            "    ",
+	flatListsByCode: true,
+}
+
+// terminalStyle uses ANSI escapes: bold emphasis, cyan code and bold green headings
+var terminalStyle = style{
+	emphasis:       "\x1b[1m",
+	emphasisEnd:    "\x1b[22m",
+	code:           "\x1b[36m",
+	codeEnd:        "\x1b[39m",
+	heading:        "\x1b[1;32m",
+	headingEnd:     "\x1b[0m",
+	block:          "    \x1b[36m",
+	blockEnd:       "\x1b[39m",
+	bullet:         "  - ",
+	hang:           "    ",
+	emphasisInCode: true,
+}
+
+// ansiRegex matches the escapes in terminalStyle, which take up no room on screen
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// renderArticle converts one part's description into text wrapped at width, marked up with st
+//
+// Code blocks are kept verbatim, list items are bulleted and in plainStyle,
+// <em> becomes *emphasis* and inline <code> becomes `code`
+func renderArticle(article string, width int, st style) (string, error) {
+	blocks, err := parseArticle(article, st)
+	if err != nil {
+		return "", err
+	}
+	return writeBlocks(blocks, width, st), nil
+}
+
+// articleParser splits the tokens of a description into blocks of marked up text
+type articleParser struct {
+	current    *block
+	blocks     []*block
+	st         style
+	inPre      bool
+	inlineCode int // How many <code>s deep we are outside a code block
+}
+
+// parseArticle splits one part's description into its blocks, marked up with st
+func parseArticle(article string, st style) ([]*block, error) {
+	dec := xml.NewDecoder(strings.NewReader(article))
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	p := &articleParser{st: st}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return p.blocks, nil
+			}
+			return nil, err
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			p.start(tok.Name.Local)
+		case xml.EndElement:
+			p.end(tok.Name.Local)
+		case xml.CharData:
+			p.text(string(tok))
+		}
+	}
+}
+
+// open starts a new block of kind
+func (p *articleParser) open(kind blockKind) {
+	p.current = &block{kind: kind}
+	p.blocks = append(p.blocks, p.current)
+}
+
+// write adds s to the current block
+func (p *articleParser) write(s string) {
+	if p.current == nil {
+		// Stray text outside any block, give it its own paragraph
+		p.open(paragraph)
+	}
+	p.current.text.WriteString(s)
+}
+
+// emphasised reports whether an <em> here gets marked up, code blocks are verbatim
+func (p *articleParser) emphasised() bool {
+	return !p.inPre && (p.inlineCode == 0 || p.st.emphasisInCode)
+}
+
+func (p *articleParser) start(tag string) {
+	switch tag {
+	case "h2":
+		p.open(heading)
+	case "p":
+		p.open(paragraph)
+	case "li":
+		p.open(item)
+	case "pre":
+		p.open(code)
+		p.inPre = true
+	case "code":
+		if !p.inPre {
+			p.write(p.st.code)
+			p.inlineCode++
+		}
+	case "em":
+		if p.emphasised() {
+			p.write(p.st.emphasis)
+		}
+	}
+}
+
+func (p *articleParser) end(tag string) {
+	switch tag {
+	case "h2", "p", "li":
+		p.current = nil
+	case "pre":
+		p.current = nil
+		p.inPre = false
+	case "code":
+		if !p.inPre {
+			p.write(p.st.codeEnd)
+			p.inlineCode--
+		}
+	case "em":
+		if p.emphasised() {
+			p.write(p.st.emphasisEnd)
+		}
+	}
+}
+
+func (p *articleParser) text(text string) {
+	if !p.inPre {
+		text = collapseSpace(text)
+	}
+	if p.current == nil && strings.TrimSpace(text) == "" {
+		// Whitespace between blocks
+		return
+	}
+	p.write(text)
+}
+
+// section is a block ready to write out
+type section struct {
+	text string
+	kind blockKind
+	flat bool // A list item written without indentation, see style.flatListsByCode
+}
+
+// sections returns the blocks that have something in them, trimmed and with
+// each list item marked flat if st needs it to be
+func sections(blocks []*block, st style) []section {
+	var out []section
+	for _, b := range blocks {
+		text := b.text.String()
+		if b.kind != code {
+			text = strings.TrimSpace(text)
+		}
+		if text != "" {
+			out = append(out, section{text: text, kind: b.kind})
+		}
+	}
+	if !st.flatListsByCode {
+		return out
+	}
+
+	// Each run of items is one list, flat if there's code either side of it
+	for start := 0; start < len(out); start++ {
+		if out[start].kind != item {
+			continue
+		}
+		end := start
+		for end < len(out) && out[end].kind == item {
+			end++
+		}
+		flat := (start > 0 && out[start-1].kind == code) || (end < len(out) && out[end].kind == code)
+		for i := start; i < end; i++ {
+			out[i].flat = flat
+		}
+		start = end
+	}
+	return out
+}
+
+// writeBlocks lays the blocks out one after another in st, wrapping prose at width
+func writeBlocks(blocks []*block, width int, st style) string {
+	var out strings.Builder
+	prev := paragraph
+	for i, s := range sections(blocks, st) {
+		if i > 0 {
+			// List items sit directly under each other, everything else gets a blank line
+			if s.kind == item && prev == item {
+				out.WriteString("\n")
+			} else {
+				out.WriteString("\n\n")
+			}
+		}
+		writeSection(&out, s, width, st)
+		prev = s.kind
+	}
+	return out.String()
+}
+
+// writeSection writes a single section to out
+func writeSection(out *strings.Builder, s section, width int, st style) {
+	switch s.kind {
+	case code:
+		writeCode(out, s.text, st)
+	case heading:
+		out.WriteString(st.heading + wrap(s.text, width, "", "") + st.headingEnd)
+	case item:
+		if s.flat {
+			out.WriteString(wrap(s.text, width, strings.TrimLeft(st.bullet, " "), ""))
+		} else {
+			out.WriteString(wrap(s.text, width, st.bullet, st.hang))
+		}
+	default:
+		out.WriteString(wrap(s.text, width, "", ""))
+	}
+}
+
+// writeCode writes a code block to out verbatim, apart from trailing whitespace
+func writeCode(out *strings.Builder, text string, st style) {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		if i > 0 {
+			out.WriteString("\n")
+		}
+		if line = strings.TrimRight(line, " \t"); line != "" {
+			out.WriteString(st.block + line + st.blockEnd)
+		}
+	}
+}
+
+// renderDescription renders every part's description on a puzzle page as a block
+// of plain text, one part after another
+func renderDescription(page []byte) (string, error) {
+	articles := extractArticles(page)
+	if len(articles) == 0 {
+		return "", ErrNoDescription
+	}
+
+	parts := make([]string, 0, len(articles))
+	for _, article := range articles {
+		text, err := renderArticle(article, WIDTH, plainStyle)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, "\n\n"), nil
+}
+
+// docComment renders a puzzle page as the block comment that heads a dayNN.go file
+func docComment(page []byte) (string, error) {
+	text, err := renderDescription(page)
+	if err != nil {
+		return "", err
+	}
+	return "/*\n" + escapeComment(text) + "\n*/\n", nil
+}
+
+// refreshDocComment adds the part two description from page to the doc comment
+// at the top of src, leaving everything after the comment exactly as it was
+//
+// If src has no doc comment, the whole description is added, if it already has
+// part two or part two isn't unlocked yet, src is returned unchanged and false
+func refreshDocComment(src, page []byte) ([]byte, bool, error) {
+	if !bytes.HasPrefix(src, []byte("/*")) {
+		header, err := docComment(page)
+		if err != nil {
+			return nil, false, err
+		}
+		return append([]byte(header), src...), true, nil
+	}
+
+	end := bytes.Index(src, []byte("*/"))
+	if end == -1 {
+		return nil, false, errors.New("unterminated doc comment")
+	}
+	header := src[:end]
+
+	articles := extractArticles(page)
+	if len(articles) == 0 {
+		return nil, false, ErrNoDescription
+	}
+	if len(articles) < 2 || bytes.Contains(header, []byte("--- Part Two ---")) {
+		return src, false, nil
+	}
+
+	partTwo, err := renderArticle(articles[1], WIDTH, plainStyle)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var out bytes.Buffer
+	out.Write(bytes.TrimRight(header, "\n"))
+	out.WriteString("\n\n")
+	out.WriteString(escapeComment(partTwo))
+	out.WriteString("\n")
+	out.Write(src[end:])
+
+	return out.Bytes(), true, nil
+}
+
+// wrap word wraps text to width, starting the first line with first and
+// every following line with rest, ANSI escapes don't count towards the width
+func wrap(text string, width int, first, rest string) string {
+	var out strings.Builder
+	line := first
+	length := visibleLen(first)
+	lineHasWord := false
+
+	for _, word := range strings.Fields(text) {
+		wordLength := visibleLen(word)
+		if lineHasWord && length+1+wordLength > width {
+			out.WriteString(line)
+			out.WriteString("\n")
+			line = rest
+			length = visibleLen(rest)
+			lineHasWord = false
+		}
+		if lineHasWord {
+			line += " "
+			length++
+		}
+		line += word
+		length += wordLength
+		lineHasWord = true
+	}
+	out.WriteString(line)
+
+	return out.String()
+}
+
+// visibleLen is the number of columns s takes up on screen, ignoring ANSI escapes
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiRegex.ReplaceAllString(s, ""))
+}
+
+// collapseSpace replaces every run of whitespace in s with a single space
+func collapseSpace(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		if s != "" {
+			return " "
+		}
+		return ""
+	}
+
+	collapsed := strings.Join(fields, " ")
+	if strings.TrimLeft(s, " \t\n\r") != s {
+		collapsed = " " + collapsed
+	}
+	if strings.TrimRight(s, " \t\n\r") != s {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+// escapeComment stops anything in text from ending the block comment early
+func escapeComment(text string) string {
+	return strings.ReplaceAll(text, "*/", "* /")
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"go/format"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+const partOneHTML = `<article class="day-desc"><h2>--- Day 1: Report Repair ---</h2><p>After saving Christmas <a href="/events">five years in a row</a>, you've decided to take a vacation at a nice resort on a tropical island. Surely, Christmas will go on without you.</p>
+<p>Specifically, they need you to <em>find the two entries that sum to <code>2020</code></em> and then multiply those two numbers together.</p>
+<p>For example, suppose your expense report contained the following:</p>
+<pre><code>1721
+979
+366
+</code></pre>
+<p>In this list, the two entries that sum to <code>2020</code> are <code>1721</code> and <code>299</code>. Multiplying them together produces <code>1721 * 299 = <em>514579</em></code>, so the correct answer is <code><em>514579</em></code>.</p>
+</article>`
+
+const partTwoHTML = `<article class="day-desc"><h2 id="part2">--- Part Two ---</h2><p>Determine the number of trees you would encounter if, for each of the following slopes:</p>
+<ul>
+<li>Right 1, down 1.</li>
+<li>Right 3, down 1. (This is the slope you already checked.)</li>
+</ul>
+<p>What do you get if you multiply them &amp; the <code>&lt;answer&gt;</code>?</p>
+</article>`
+
+func page(articles ...string) []byte {
+	return []byte("<html><body><main>\n" + strings.Join(articles, "\n") + "\n<p>Answer: <input type=\"text\" name=\"answer\"/></p></main></body></html>")
+}
+
+func TestRenderArticle(t *testing.T) {
+	is := is.New(t)
+
+	got, err := renderArticle(partOneHTML, WIDTH, plainStyle)
+	is.NoErr(err)
+
+	want := "--- Day 1: Report Repair ---\n" +
+		"\n" +
+		"After saving Christmas five years in a row, you've decided to take a vacation at\n" +
+		"a nice resort on a tropical island. Surely, Christmas will go on without you.\n" +
+		"\n" +
+		"Specifically, they need you to *find the two entries that sum to `2020`* and\n" +
+		"then multiply those two numbers together.\n" +
+		"\n" +
+		"For example, suppose your expense report contained the following:\n" +
+		"\n" +
+		"\t1721\n" +
+		"\t979\n" +
+		"\t366\n" +
+		"\n" +
+		"In this list, the two entries that sum to `2020` are `1721` and `299`.\n" +
+		"Multiplying them together produces `1721 * 299 = 514579`, so the correct answer\n" +
+		"is `514579`."
+
+	is.Equal(got, want)
+}
+
+func TestRenderArticleList(t *testing.T) {
+	is := is.New(t)
+
+	got, err := renderArticle(partTwoHTML, 40, plainStyle)
+	is.NoErr(err)
+
+	want := "--- Part Two ---\n" +
+		"\n" +
+		"Determine the number of trees you would\n" +
+		"encounter if, for each of the following\n" +
+		"slopes:\n" +
+		"\n" +
+		"  - Right 1, down 1.\n" +
+		"  - Right 3, down 1. (This is the slope\n" +
+		"    you already checked.)\n" +
+		"\n" +
+		"What do you get if you multiply them &\n" +
+		"the `<answer>`?"
+
+	is.Equal(got, want)
+}
+
+func TestDocComment(t *testing.T) {
+	is := is.New(t)
+
+	got, err := docComment(page(partOneHTML))
+	is.NoErr(err)
+	is.True(strings.HasPrefix(got, "/*\n--- Day 1: Report Repair ---\n"))
+	is.True(strings.HasSuffix(got, "is `514579`.\n*/\n"))
+
+	_, err = docComment([]byte("<html>Please log in</html>"))
+	is.Equal(err, ErrNoDescription)
+}
+
+func TestDocCommentGofmt(t *testing.T) {
+	tests := []struct {
+		name    string
+		article string
+		want    string // The layout of the lists and code blocks
+	}{
+		{
+			name: "list then code",
+			article: `<article class="day-desc"><h2>--- Day 6: Custom Customs ---</h2><p>For example:</p><ul><li>Someone answered <code>a</code>.</li><li>Someone else answered <code>b</code>.</li></ul><pre><code>abcx
+  abcy
+</code></pre><p>That's it.</p></article>`,
+			want: "For example:\n\n- Someone answered `a`.\n- Someone else answered `b`.\n\n\tabcx\n\t  abcy\n\nThat's it.",
+		},
+		{
+			name: "code then list",
+			article: `<article class="day-desc"><h2>--- Day 6: Custom Customs ---</h2><pre><code>abcx
+</code></pre><ul><li>One.</li><li>Two.</li></ul><p>That's it.</p></article>`,
+			want: "\tabcx\n\n- One.\n- Two.\n\nThat's it.",
+		},
+		{
+			name:    "list on its own",
+			article: partTwoHTML,
+			want:    "slopes:\n\n  - Right 1, down 1.\n  - Right 3, down 1.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			header, err := docComment(page(tt.article))
+			is.NoErr(err)
+			is.True(strings.Contains(header, tt.want)) // Unexpected layout
+
+			// Scaffolding runs the day through gofmt, it mustn't touch the comment
+			src := header + "package day06\n"
+			formatted, err := format.Source([]byte(src))
+			is.NoErr(err)
+			is.Equal(string(formatted), src)
+		})
+	}
+}
+
+func TestRefreshDocComment(t *testing.T) {
+	is := is.New(t)
+
+	header, err := docComment(page(partOneHTML))
+	is.NoErr(err)
+	code := "package main\n\nfunc part1() int {\n\treturn 0 /* for now */\n}\n"
+	src := []byte(header + code)
+
+	// Part two not unlocked yet
+	got, changed, err := refreshDocComment(src, page(partOneHTML))
+	is.NoErr(err)
+	is.True(!changed)
+	is.Equal(got, src)
+
+	got, changed, err = refreshDocComment(src, page(partOneHTML, partTwoHTML))
+	is.NoErr(err)
+	is.True(changed)
+	is.True(strings.HasSuffix(string(got), "the `<answer>`?\n*/\n"+code)) // Code untouched
+	is.True(strings.Contains(string(got), "is `514579`.\n\n--- Part Two ---\n"))
+
+	// Running it again is a no-op
+	again, changed, err := refreshDocComment(got, page(partOneHTML, partTwoHTML))
+	is.NoErr(err)
+	is.True(!changed)
+	is.Equal(again, got)
+}
+
+func TestRefreshDocCommentNoHeader(t *testing.T) {
+	is := is.New(t)
+	src := []byte("package main\n")
+
+	got, changed, err := refreshDocComment(src, page(partOneHTML, partTwoHTML))
+	is.NoErr(err)
+	is.True(changed)
+	is.True(strings.HasPrefix(string(got), "/*\n--- Day 1: Report Repair ---\n"))
+	is.True(strings.HasSuffix(string(got), "--- Part Two ---\n\nDetermine the number of trees you would encounter if, for each of the following\nslopes:\n\n  - Right 1, down 1.\n  - Right 3, down 1. (This is the slope you already checked.)\n\nWhat do you get if you multiply them & the `<answer>`?\n*/\npackage main\n"))
+}
+
+func TestWrap(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		width int
+		want  string
+	}{
+		{name: "fits", text: "one two three", width: 20, want: "one two three"},
+		{name: "wraps", text: "one two three", width: 8, want: "one two\nthree"},
+		{name: "long word", text: "a supercalifragilistic word", width: 5, want: "a\nsupercalifragilistic\nword"},
+		{name: "empty", text: "", width: 5, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(wrap(tt.text, tt.width, "", ""), tt.want)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// readPuzzle renders the description of part of a cached puzzle page, part 0 is every part there is
+func readPuzzle(page []byte, part, width int, st style) (string, error) {
+	articles := extractArticles(page)
+	if len(articles) == 0 {
+		return "", ErrNoDescription
+	}
+
+	if part != 0 {
+		if part > len(articles) {
+			return "", fmt.Errorf("part %d isn't in the cached page, once it unlocks run 'new --refresh' to fetch it", part)
+		}
+		articles = articles[part-1 : part]
+	}
+
+	parts := make([]string, 0, len(articles))
+	for _, article := range articles {
+		text, err := renderArticle(article, width, st)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, text)
+	}
+
+	return strings.Join(parts, "\n\n") + "\n", nil
+}
+
+// isTerminal reports whether f looks like a terminal rather than a file or a pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// runRead implements the 'read' command, showing a day's puzzle from the cache
+func runRead(args []string) error {
+	var common commonFlags
+	flags := flag.NewFlagSet("read", flag.ContinueOnError)
+	common.register(flags)
+	part := flags.Int("part", 0, "Only show this part of the puzzle (default every part in the cache)")
+	width := flags.Int("width", WIDTH, "Column to wrap the text at")
+	plain := flags.Bool("plain", false, "No colours, the default when $NO_COLOR is set or output isn't a terminal")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) != 1 {
+		return fmt.Errorf("read expects a single arg 'day', got: %v", args)
+	}
+	day, err := utils.ParseDay(args[0])
+	if err != nil {
+		return err
+	}
+	if *part < 0 || *part > 2 {
+		return fmt.Errorf("part should be 1 or 2, got: %d", *part)
+	}
+
+	e, err := common.env()
+	if err != nil {
+		return err
+	}
+
+	page, _, err := e.cache.Get(e.year, day, kindPuzzle)
+	if err != nil {
+		if errors.Is(err, ErrNotCached) {
+			return fmt.Errorf("day %d has not been fetched yet, run 'new %d' first: %w", day, day, err)
+		}
+		return err
+	}
+
+	st := terminalStyle
+	if _, noColor := os.LookupEnv("NO_COLOR"); *plain || noColor || !isTerminal(os.Stdout) {
+		st = plainStyle
+	}
+
+	text, err := readPuzzle(page, *part, *width, st)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(os.Stdout, text)
+	return err
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestReadPuzzle(t *testing.T) {
+	is := is.New(t)
+	both := page(partOneHTML, partTwoHTML)
+
+	all, err := readPuzzle(both, 0, WIDTH, plainStyle)
+	is.NoErr(err)
+	is.True(strings.HasPrefix(all, "--- Day 1: Report Repair ---\n"))
+	is.True(strings.Contains(all, "--- Part Two ---"))
+
+	two, err := readPuzzle(both, 2, WIDTH, plainStyle)
+	is.NoErr(err)
+	is.True(strings.HasPrefix(two, "--- Part Two ---\n"))
+	is.True(!strings.Contains(two, "Report Repair"))
+
+	_, err = readPuzzle(page(partOneHTML), 2, WIDTH, plainStyle)
+	is.True(err != nil) // Part two isn't in the cache yet
+
+	_, err = readPuzzle([]byte("<html></html>"), 0, WIDTH, plainStyle)
+	is.Equal(err, ErrNoDescription)
+}
+
+func TestReadPuzzleTerminal(t *testing.T) {
+	is := is.New(t)
+
+	got, err := readPuzzle(page(partOneHTML), 1, WIDTH, terminalStyle)
+	is.NoErr(err)
+
+	is.True(strings.HasPrefix(got, "\x1b[1;32m--- Day 1: Report Repair ---\x1b[0m\n"))
+	is.True(strings.Contains(got, "\x1b[1mfind the two entries that sum to \x1b[36m2020\x1b[39m\x1b[22m"))
+	is.True(strings.Contains(got, "    \x1b[36m1721\x1b[39m\n"))
+	is.True(strings.Contains(got, "\x1b[36m\x1b[1m514579\x1b[22m\x1b[39m")) // Emphasis in code is kept
+
+	// The escapes don't count towards the width
+	is.True(strings.Contains(got, "After saving Christmas five years in a row, you've decided to take a vacation at\n"))
+	for _, line := range strings.Split(got, "\n") {
+		is.True(visibleLen(line) <= WIDTH)
+	}
+}
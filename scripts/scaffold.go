@@ -0,0 +1,200 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// role is what a file in a day's directory is for
+type role int
+
+const (
+	roleCode role = iota
+	roleTest
+	roleInput
+	roleCommand // The day's own main package
+	roleExample // The first example from the description, for --example 1
+)
+
+// action is what scaffolding will do to a file
+type action int
+
+const (
+	actionCreate action = iota
+	actionOverwrite
+	actionSkip
+)
+
+func (a action) String() string {
+	switch a {
+	case actionCreate:
+		return "create"
+	case actionOverwrite:
+		return "overwrite"
+	default:
+		return "skip"
+	}
+}
+
+// scaffoldMode controls what happens to a day that's already there
+type scaffoldMode struct {
+	force     bool // Fill in missing files and refresh the input, never touching existing code
+	onlyInput bool // Only write the input
+}
+
+// plannedFile is one file scaffolding will (or won't) write
+type plannedFile struct {
+	path   string // Relative to the project root
+	role   role
+	action action
+}
+
+// dayPlan is everything scaffolding a day will do
+type dayPlan struct {
+	dir    string // Relative to the project root
+	files  []plannedFile
+	create bool // Whether the day's directory is new
+}
+
+// dayDir returns the directory for a day relative to the project root e.g. 2020/day01
+func dayDir(year, day int) string {
+	return filepath.Join(strconv.Itoa(year), dayName(day))
+}
+
+// dayName returns the name of a day's directory and the stem of its files e.g. day01
+func dayName(day int) string {
+	return fmt.Sprintf("day%02d", day)
+}
+
+// planDay works out what scaffolding year's day under root would do in mode without touching anything
+func planDay(root string, year, day int, mode scaffoldMode) (*dayPlan, error) {
+	dir := dayDir(year, day)
+	name := dayName(day)
+	plan := &dayPlan{
+		dir:    dir,
+		create: !exists(filepath.Join(root, dir)),
+	}
+
+	if !plan.create && !mode.force && !mode.onlyInput {
+		return nil, fmt.Errorf("%s already exists, use --force to fill in missing files and refresh the input or --only-input", filepath.Join(root, dir))
+	}
+
+	files := []plannedFile{
+		{path: filepath.Join(dir, name+".go"), role: roleCode},
+		{path: filepath.Join(dir, name+"_test.go"), role: roleTest},
+		{path: filepath.Join(dir, name+".txt"), role: roleInput},
+		{path: filepath.Join(dir, "cmd", name, "main.go"), role: roleCommand},
+		{path: filepath.Join(dir, "testdata", "example1.txt"), role: roleExample},
+	}
+
+	for i, file := range files {
+		there := exists(filepath.Join(root, file.path))
+		switch {
+		case mode.onlyInput && file.role != roleInput:
+			files[i].action = actionSkip
+		case !there:
+			files[i].action = actionCreate
+		case file.role == roleInput:
+			// Inputs never change so there's no harm refreshing one
+			files[i].action = actionOverwrite
+		default:
+			// Never clobber solution code
+			files[i].action = actionSkip
+		}
+	}
+	plan.files = files
+
+	return plan, nil
+}
+
+// needs reports whether the plan writes a file with role r
+func (p *dayPlan) needs(r role) bool {
+	for _, file := range p.files {
+		if file.role == r && file.action != actionSkip {
+			return true
+		}
+	}
+	return false
+}
+
+// skip stops the plan writing any file with role r
+func (p *dayPlan) skip(r role) {
+	for i := range p.files {
+		if p.files[i].role == r {
+			p.files[i].action = actionSkip
+		}
+	}
+}
+
+// print writes the plan for a human to read
+func (p *dayPlan) print(w io.Writer) {
+	if p.create {
+		fmt.Fprintf(w, "create    %s%c\n", p.dir, filepath.Separator)
+	}
+	for _, file := range p.files {
+		fmt.Fprintf(w, "%-9s %s\n", file.action, file.path)
+	}
+}
+
+// apply carries out the plan under root, contents holds what to write for each role needed
+//
+// A new day is staged in a temporary directory and renamed into place in one
+// go so a failure part way through never leaves a half made day behind, files
+// in an existing day are each written atomically
+func (p *dayPlan) apply(root string, contents map[role][]byte) error {
+	if !p.create {
+		for _, file := range p.files {
+			if file.action == actionSkip {
+				continue
+			}
+			if err := writeFile(filepath.Join(root, file.path), contents[file.role]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// The first day of a new year needs somewhere to go
+	year := filepath.Join(root, filepath.Dir(p.dir))
+	if err := os.MkdirAll(year, 0o755); err != nil {
+		return err
+	}
+
+	staging, err := os.MkdirTemp(year, "."+filepath.Base(p.dir)+"-*")
+	if err != nil {
+		return err
+	}
+	// Does nothing once the rename has succeeded
+	defer os.RemoveAll(staging)
+
+	for _, file := range p.files {
+		if file.action == actionSkip {
+			continue
+		}
+		rel, err := filepath.Rel(p.dir, file.path)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filepath.Join(staging, rel), contents[file.role]); err != nil {
+			return err
+		}
+	}
+
+	// MkdirTemp makes directories only we can use
+	if err := os.Chmod(staging, 0o755); err != nil {
+		return err
+	}
+
+	return os.Rename(staging, filepath.Join(root, p.dir))
+}
+
+// writeFile atomically writes data to path, making any directories it needs
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data)
+}
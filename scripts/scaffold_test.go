@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+var testContents = map[role][]byte{
+	roleCode:    []byte("package day06\n"),
+	roleTest:    []byte("package day06\n\nimport \"testing\"\n"),
+	roleInput:   []byte("1721\n979\n"),
+	roleCommand: []byte("package main\n"),
+	roleExample: []byte("1721\n"),
+}
+
+func TestScaffoldNewDay(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+
+	plan, err := planDay(root, 2020, 6, scaffoldMode{})
+	is.NoErr(err)
+	is.True(plan.create)
+	for _, file := range plan.files {
+		is.Equal(file.action, actionCreate)
+	}
+
+	is.NoErr(plan.apply(root, testContents))
+
+	got, err := os.ReadFile(filepath.Join(root, "2020", "day06", "day06.txt"))
+	is.NoErr(err)
+	is.Equal(got, testContents[roleInput])
+
+	got, err = os.ReadFile(filepath.Join(root, "2020", "day06", "cmd", "day06", "main.go"))
+	is.NoErr(err)
+	is.Equal(got, testContents[roleCommand])
+
+	// Nothing left over from staging
+	entries, err := os.ReadDir(filepath.Join(root, "2020"))
+	is.NoErr(err)
+	is.Equal(len(entries), 1)
+	is.Equal(entries[0].Name(), "day06")
+}
+
+func TestScaffoldExistingDay(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+
+	// A day with some solution code but where writing the test file failed
+	dir := filepath.Join(root, "2020", "day06")
+	is.NoErr(os.MkdirAll(dir, 0o755))
+	solution := []byte("package main\n\nfunc part1() int { return 42 }\n")
+	is.NoErr(os.WriteFile(filepath.Join(dir, "day06.go"), solution, 0o644))
+	is.NoErr(os.WriteFile(filepath.Join(dir, "day06.txt"), []byte("stale"), 0o644))
+
+	_, err := planDay(root, 2020, 6, scaffoldMode{})
+	is.True(err != nil) // Refuses without --force
+
+	plan, err := planDay(root, 2020, 6, scaffoldMode{force: true})
+	is.NoErr(err)
+	is.True(!plan.create)
+	is.Equal(plan.files[0].action, actionSkip)      // Code
+	is.Equal(plan.files[1].action, actionCreate)    // Test
+	is.Equal(plan.files[2].action, actionOverwrite) // Input
+	is.Equal(plan.files[3].action, actionCreate)    // Command
+	is.True(!plan.needs(roleCode))
+
+	is.NoErr(plan.apply(root, testContents))
+
+	got, err := os.ReadFile(filepath.Join(dir, "day06.go"))
+	is.NoErr(err)
+	is.Equal(got, solution) // Untouched
+
+	got, err = os.ReadFile(filepath.Join(dir, "day06_test.go"))
+	is.NoErr(err)
+	is.Equal(got, testContents[roleTest])
+
+	got, err = os.ReadFile(filepath.Join(dir, "day06.txt"))
+	is.NoErr(err)
+	is.Equal(got, testContents[roleInput])
+
+	got, err = os.ReadFile(filepath.Join(dir, "cmd", "day06", "main.go"))
+	is.NoErr(err)
+	is.Equal(got, testContents[roleCommand])
+}
+
+func TestScaffoldOnlyInput(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+
+	plan, err := planDay(root, 2020, 7, scaffoldMode{onlyInput: true})
+	is.NoErr(err)
+	is.True(plan.needs(roleInput))
+	is.True(!plan.needs(roleCode))
+	is.True(!plan.needs(roleTest))
+
+	is.NoErr(plan.apply(root, map[role][]byte{roleInput: testContents[roleInput]}))
+
+	entries, err := os.ReadDir(filepath.Join(root, "2020", "day07"))
+	is.NoErr(err)
+	is.Equal(len(entries), 1)
+	is.Equal(entries[0].Name(), "day07.txt")
+
+	// And again is fine, it just refreshes
+	plan, err = planDay(root, 2020, 7, scaffoldMode{onlyInput: true})
+	is.NoErr(err)
+	is.Equal(plan.files[2].action, actionOverwrite)
+}
+
+func TestScaffoldFailureLeavesNothing(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+
+	plan, err := planDay(root, 2020, 8, scaffoldMode{})
+	is.NoErr(err)
+
+	// Something's got in the way since we planned
+	is.NoErr(os.MkdirAll(filepath.Join(root, "2020"), 0o755))
+	is.NoErr(os.WriteFile(filepath.Join(root, "2020", "day08"), []byte("oops"), 0o644))
+
+	is.True(plan.apply(root, testContents) != nil)
+
+	entries, err := os.ReadDir(filepath.Join(root, "2020"))
+	is.NoErr(err)
+	is.Equal(len(entries), 1) // Just the thing in the way, no staging dir
+}
+
+func TestDayPlanPrint(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+
+	plan, err := planDay(root, 2020, 9, scaffoldMode{})
+	is.NoErr(err)
+
+	buf := &bytes.Buffer{}
+	plan.print(buf)
+
+	sep := string(filepath.Separator)
+	dir := "2020" + sep + "day09"
+	want := "create    " + dir + sep + "\n" +
+		"create    " + dir + sep + "day09.go\n" +
+		"create    " + dir + sep + "day09_test.go\n" +
+		"create    " + dir + sep + "day09.txt\n" +
+		"create    " + dir + sep + "cmd" + sep + "day09" + sep + "main.go\n" +
+		"create    " + dir + sep + "testdata" + sep + "example1.txt\n"
+	is.Equal(buf.String(), want)
+}
@@ -0,0 +1,291 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/testjson"
+)
+
+// CALENDARTTL is how long a cached calendar is good for, the same courtesy as the leaderboard
+const CALENDARTTL = 15 * time.Minute
+
+// calendarDayRegex matches a day on the calendar page, the aria-label says how many stars we have
+var calendarDayRegex = regexp.MustCompile(`aria-label="Day (\d+)(?:, (one star|two stars))?"`)
+
+// parseCalendar returns the stars earned on each day from a year's calendar page
+func parseCalendar(page []byte) map[int]int {
+	stars := make(map[int]int)
+	for _, match := range calendarDayRegex.FindAllSubmatch(page, -1) {
+		day, err := strconv.Atoi(string(match[1]))
+		if err != nil {
+			continue
+		}
+		switch string(match[2]) {
+		case "one star":
+			stars[day] = 1
+		case "two stars":
+			stars[day] = 2
+		default:
+			stars[day] = 0
+		}
+	}
+	return stars
+}
+
+// Calendar gets the raw HTML of a year's calendar page
+func (f *Fetcher) Calendar(year int) ([]byte, error) {
+	return f.do(http.MethodGet, fmt.Sprintf("/%d", year), nil)
+}
+
+// loadCalendar returns the year's calendar page, from the cache if it's younger
+// than CALENDARTTL at now or we're offline, otherwise from the site
+func loadCalendar(cache *Cache, connect func() (*Fetcher, error), year int, offline bool, now time.Time) ([]byte, error) {
+	cached, meta, cacheErr := cache.Get(year, 0, kindCalendar)
+	if cacheErr == nil && (offline || now.Sub(meta.FetchedAt) < CALENDARTTL) {
+		return cached, nil
+	}
+	if offline {
+		return nil, fmt.Errorf("cannot show stars with --offline: %w", cacheErr)
+	}
+
+	fetcher, err := connect()
+	if err == nil {
+		var page []byte
+		page, err = fetcher.Calendar(year)
+		if err == nil {
+			if _, err := cache.Put(year, 0, kindCalendar, page); err != nil {
+				return nil, fmt.Errorf("could not cache calendar: %w", err)
+			}
+			return page, nil
+		}
+	}
+
+	if cacheErr == nil {
+		fmt.Fprintf(os.Stderr, "warning: using calendar from %s: %v\n", meta.FetchedAt.Local().Format(time.Kitchen), err)
+		return cached, nil
+	}
+	return nil, err
+}
+
+// localTests reports which parts of each day have passing tests
+type localTests map[int][2]bool
+
+// runLocalTests runs the part 1 and part 2 tests of each of year's days under root,
+// a part counts as solved locally if it has at least one test and they all pass
+func runLocalTests(root string, year int, days []int) (localTests, error) {
+	results := make(localTests)
+	if len(days) == 0 {
+		return results, nil
+	}
+
+	args := []string{"test", "-json", "-count=1", "-run", "Part1|Part2"}
+	for _, day := range days {
+		args = append(args, "./"+filepath.ToSlash(dayDir(year, day)))
+	}
+	cmd := exec.Command("go", args...)
+	cmd.Dir = root
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		// go test exits non-zero when tests fail, that's fine, but no output means it didn't run at all
+		return nil, fmt.Errorf("could not run tests: %w", err)
+	}
+
+	return parseTestEvents(bytes.NewReader(out))
+}
+
+// parseTestEvents works out which parts pass from a stream of `go test -json` events
+func parseTestEvents(r io.Reader) (localTests, error) {
+	type key struct {
+		day  int
+		part int
+	}
+	passed := make(map[key]bool)
+	failed := make(map[key]bool)
+
+	err := testjson.Read(r, func(event testjson.Event) {
+		day, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(event.Package), "day"))
+		if err != nil {
+			return
+		}
+		part := 1
+		if strings.Contains(event.Test, "Part2") {
+			part = 2
+		}
+		switch event.Action {
+		case "pass":
+			passed[key{day, part}] = true
+		case "fail":
+			failed[key{day, part}] = true
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make(localTests)
+	for k := range passed {
+		if failed[k] {
+			continue
+		}
+		parts := results[k.day]
+		parts[k.part-1] = true
+		results[k.day] = parts
+	}
+	return results, nil
+}
+
+// DayStatus is everything we know about how a day is going
+type DayStatus struct {
+	State      string `json:"state"`
+	Day        int    `json:"day"`
+	Stars      int    `json:"stars"`
+	Attempts   int    `json:"attempts"`
+	Unlocked   bool   `json:"unlocked"`
+	Scaffolded bool   `json:"scaffolded"`
+	Part1Local bool   `json:"part1_local"`
+	Part2Local bool   `json:"part2_local"`
+}
+
+// state summarises a day's status in a few words, most progress wins
+func (d DayStatus) state() string {
+	switch {
+	case d.Stars == 2:
+		return "2 stars"
+	case d.Stars == 1 && d.Part2Local:
+		return "1 star, part 2 solved locally"
+	case d.Stars == 1:
+		return "1 star"
+	case d.Attempts > 0:
+		return "submitted"
+	case d.Part2Local:
+		return "part 2 solved locally"
+	case d.Part1Local:
+		return "part 1 solved locally"
+	case d.Scaffolded:
+		return "scaffolded"
+	case d.Unlocked:
+		return "not started"
+	default:
+		return "locked"
+	}
+}
+
+// dayStatuses cross references the site's stars with what's on disk and in the submission history
+func dayStatuses(root string, cache *Cache, year int, stars map[int]int, tests localTests, now time.Time) ([]DayStatus, error) {
+	statuses := make([]DayStatus, 0, 25)
+	for day := 1; day <= 25; day++ {
+		history, err := loadHistory(cache, year, day)
+		if err != nil {
+			return nil, err
+		}
+
+		d := DayStatus{
+			Day:        day,
+			Stars:      stars[day],
+			Attempts:   len(history.Attempts),
+			Unlocked:   !now.Before(unlockTime(year, day)),
+			Scaffolded: exists(filepath.Join(root, dayDir(year, day), dayName(day)+".go")),
+			Part1Local: tests[day][0],
+			Part2Local: tests[day][1],
+		}
+		// The history knows about stars even if we couldn't get the calendar
+		if history.Solved(1) && d.Stars < 1 {
+			d.Stars = 1
+		}
+		if history.Solved(2) && d.Stars < 2 {
+			d.Stars = 2
+		}
+		d.State = d.state()
+		statuses = append(statuses, d)
+	}
+	return statuses, nil
+}
+
+// renderStatus writes the statuses as a table
+func renderStatus(w io.Writer, statuses []DayStatus) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Day\tStars\tLocal\tSubmissions\tStatus")
+	total := 0
+	for _, d := range statuses {
+		total += d.Stars
+		local := "-"
+		switch {
+		case d.Part1Local && d.Part2Local:
+			local = "parts 1 & 2"
+		case d.Part1Local:
+			local = "part 1"
+		case d.Part2Local:
+			local = "part 2"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%d\t%s\n", d.Day, strings.Repeat("*", d.Stars)+strings.Repeat(".", 2-d.Stars), local, d.Attempts, d.State)
+	}
+	fmt.Fprintf(tw, "\t%d/50\t\t\t\n", total)
+	return tw.Flush()
+}
+
+// runStatus implements the 'status' command
+func runStatus(args []string) error {
+	var common commonFlags
+	flags := flag.NewFlagSet("status", flag.ContinueOnError)
+	common.register(flags)
+	offline := flags.Bool("offline", false, "Use the cached calendar rather than asking the site")
+	tests := flags.Bool("tests", true, "Run each day's part 1 and part 2 tests to see what's solved locally")
+	asJSON := flags.Bool("json", false, "Print the status as JSON")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	e, err := common.env()
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	// No stars is better than no status
+	stars := make(map[int]int)
+	page, err := loadCalendar(e.cache, e.connect, e.year, *offline, now)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: showing status without stars from the site: %v\n", err)
+	} else {
+		stars = parseCalendar(page)
+	}
+
+	local := make(localTests)
+	if *tests {
+		var scaffolded []int
+		for day := 1; day <= 25; day++ {
+			if exists(filepath.Join(e.root, dayDir(e.year, day), dayName(day)+".go")) {
+				scaffolded = append(scaffolded, day)
+			}
+		}
+		local, err = runLocalTests(e.root, e.year, scaffolded)
+		if err != nil {
+			return err
+		}
+	}
+
+	statuses, err := dayStatuses(e.root, e.cache, e.year, stars, local, now)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statuses)
+	}
+	return renderStatus(os.Stdout, statuses)
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestParseCalendar(t *testing.T) {
+	is := is.New(t)
+	stars := parseCalendar(readFixture(t, "calendar.html"))
+	is.Equal(stars, map[int]int{1: 2, 2: 2, 3: 1, 4: 0, 5: 0})
+}
+
+func TestParseTestEvents(t *testing.T) {
+	is := is.New(t)
+	events := `{"Action":"run","Package":"example/day01","Test":"TestPart1"}
+{"Action":"pass","Package":"example/day01","Test":"TestPart1"}
+{"Action":"pass","Package":"example/day01","Test":"TestPart2"}
+{"Action":"pass","Package":"example/day01"}
+{"Action":"pass","Package":"example/day02","Test":"TestPart1"}
+{"Action":"pass","Package":"example/day02","Test":"TestExamplePart2"}
+{"Action":"fail","Package":"example/day02","Test":"TestPart2"}
+# example/day03
+day03/day03.go:4:2: undefined: nope
+{"Action":"fail","Package":"example/day03"}
+`
+	got, err := parseTestEvents(strings.NewReader(events))
+	is.NoErr(err)
+	is.Equal(got, localTests{1: {true, true}, 2: {true, false}})
+}
+
+func TestDayStatusState(t *testing.T) {
+	tests := []struct {
+		name   string
+		status DayStatus
+		want   string
+	}{
+		{name: "locked", status: DayStatus{}, want: "locked"},
+		{name: "not started", status: DayStatus{Unlocked: true}, want: "not started"},
+		{name: "scaffolded", status: DayStatus{Unlocked: true, Scaffolded: true}, want: "scaffolded"},
+		{name: "part 1 local", status: DayStatus{Scaffolded: true, Part1Local: true}, want: "part 1 solved locally"},
+		{name: "part 2 local", status: DayStatus{Part1Local: true, Part2Local: true}, want: "part 2 solved locally"},
+		{name: "submitted", status: DayStatus{Part1Local: true, Attempts: 2}, want: "submitted"},
+		{name: "1 star", status: DayStatus{Stars: 1, Attempts: 1, Part1Local: true}, want: "1 star"},
+		{name: "1 star part 2 local", status: DayStatus{Stars: 1, Part2Local: true}, want: "1 star, part 2 solved locally"},
+		{name: "2 stars", status: DayStatus{Stars: 2, Part2Local: true}, want: "2 stars"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(tt.status.state(), tt.want)
+		})
+	}
+}
+
+func TestDayStatuses(t *testing.T) {
+	is := is.New(t)
+	root := t.TempDir()
+	cache := NewCache(t.TempDir())
+
+	for _, name := range []string{"day01", "day02", "day03"} {
+		is.NoErr(os.MkdirAll(filepath.Join(root, "2020", name), 0o755))
+		is.NoErr(os.WriteFile(filepath.Join(root, "2020", name, name+".go"), []byte("package main\n"), 0o644))
+	}
+	// Another year's days don't count
+	is.NoErr(os.MkdirAll(filepath.Join(root, "2021", "day04"), 0o755))
+	is.NoErr(os.WriteFile(filepath.Join(root, "2021", "day04", "day04.go"), []byte("package main\n"), 0o644))
+
+	// Day 2 was answered right but the calendar hasn't caught up
+	history := &History{Attempts: []Attempt{{Part: 1, Answer: "12", Outcome: Correct}}}
+	is.NoErr(history.save(cache, 2020, 2))
+	// Day 3's part 2 went in too early, that's no star
+	history = &History{Attempts: []Attempt{{Part: 1, Answer: "7", Outcome: TooLow}, {Part: 2, Answer: "8", Outcome: AlreadySolved}}}
+	is.NoErr(history.save(cache, 2020, 3))
+
+	stars := map[int]int{1: 2}
+	tests := localTests{1: {true, true}, 2: {true, true}}
+	now := unlockTime(2020, 4).Add(time.Hour)
+
+	statuses, err := dayStatuses(root, cache, 2020, stars, tests, now)
+	is.NoErr(err)
+	is.Equal(len(statuses), 25)
+
+	is.Equal(statuses[0].State, "2 stars")
+	is.Equal(statuses[1].Stars, 1)
+	is.Equal(statuses[1].State, "1 star, part 2 solved locally")
+	is.Equal(statuses[2].State, "submitted")
+	is.Equal(statuses[2].Stars, 0)
+	is.Equal(statuses[2].Attempts, 2)
+	is.Equal(statuses[3].State, "not started")
+	is.Equal(statuses[4].State, "locked")
+}
+
+func TestScaffoldedDayNotSolved(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds a scaffolded day")
+	}
+	is := is.New(t)
+
+	// A copy of just enough of the module for a scaffolded day to build
+	root := t.TempDir()
+	for _, name := range []string{"go.mod", "go.sum", filepath.Join("utils", "root.go"), filepath.Join("solution", "solution.go"), filepath.Join("solution", "run.go"), filepath.Join("solution", "answers.go")} {
+		src, err := os.ReadFile(filepath.Join("..", name))
+		is.NoErr(err)
+		is.NoErr(os.MkdirAll(filepath.Dir(filepath.Join(root, name)), 0o755))
+		is.NoErr(os.WriteFile(filepath.Join(root, name), src, 0o644))
+	}
+
+	tmpl, err := loadTemplates(t.TempDir())
+	is.NoErr(err)
+	contents, err := renderDay(tmpl, dayData{Year: 2020, Day: 6, Name: "day06", Example: "abc\n"})
+	is.NoErr(err)
+	contents[roleInput] = []byte("abc\n")
+	contents[roleExample] = []byte("abc\n")
+
+	plan, err := planDay(root, 2020, 6, scaffoldMode{})
+	is.NoErr(err)
+	is.NoErr(plan.apply(root, contents))
+
+	tests, err := runLocalTests(root, 2020, []int{6})
+	is.NoErr(err)
+	is.Equal(tests[6], [2]bool{}) // The placeholder example test counted as solving the day
+
+	statuses, err := dayStatuses(root, NewCache(t.TempDir()), 2020, nil, tests, unlockTime(2020, 6).Add(time.Hour))
+	is.NoErr(err)
+	is.Equal(statuses[5].State, "scaffolded")
+}
+
+func TestRenderStatus(t *testing.T) {
+	is := is.New(t)
+	statuses := []DayStatus{
+		{Day: 1, Stars: 2, Part1Local: true, Part2Local: true, State: "2 stars"},
+		{Day: 2, Stars: 1, Attempts: 3, Part1Local: true, State: "1 star"},
+		{Day: 3, Scaffolded: true, Unlocked: true, State: "scaffolded"},
+	}
+
+	buf := &bytes.Buffer{}
+	is.NoErr(renderStatus(buf, statuses))
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	is.Equal(len(lines), 5) // Header, 3 days and the total
+	is.True(strings.HasPrefix(lines[1], "1    **     parts 1 & 2"))
+	is.True(strings.Contains(lines[2], "*."))
+	is.True(strings.HasSuffix(strings.TrimSpace(lines[3]), "scaffolded"))
+	is.True(strings.Contains(lines[4], "3/50"))
+}
+
+func TestLoadCalendar(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+	site := newFakeSite(t)
+	fixture := readFixture(t, "calendar.html")
+	site.Config.Handler.(*http.ServeMux).HandleFunc("/2020", func(w http.ResponseWriter, r *http.Request) {
+		site.mu.Lock()
+		site.requests = append(site.requests, r.Method+" "+r.URL.Path)
+		site.mu.Unlock()
+		w.Write(fixture)
+	})
+	connect := func() (*Fetcher, error) { return site.fetcher(t), nil }
+
+	// Nothing cached yet so offline has nothing to show
+	_, err := loadCalendar(cache, connect, 2020, true, time.Now())
+	is.True(errors.Is(err, ErrNotCached))
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		got, err := loadCalendar(cache, connect, 2020, false, now)
+		is.NoErr(err)
+		is.Equal(got, fixture)
+	}
+	is.Equal(len(site.requests), 1) // The rest came from the cache
+
+	_, err = loadCalendar(cache, connect, 2020, false, now.Add(CALENDARTTL+time.Minute))
+	is.NoErr(err)
+	is.Equal(len(site.requests), 2)
+
+	// However old it is, offline uses the cache
+	_, err = loadCalendar(cache, connect, 2020, true, now.Add(24*time.Hour))
+	is.NoErr(err)
+	is.Equal(len(site.requests), 2)
+
+	broken := func() (*Fetcher, error) { return nil, errors.New("offline") }
+	got, err := loadCalendar(cache, broken, 2020, false, now.Add(time.Hour))
+	is.NoErr(err)
+	is.Equal(got, fixture)
+}
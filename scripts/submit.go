@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// Outcome is what the site made of a submitted answer
+type Outcome int
+
+const (
+	Unknown       Outcome = iota // The response didn't match anything we know about
+	Correct                      // That's the right answer!
+	Wrong                        // That's not the right answer, with no hint which way
+	TooHigh                      // That's not the right answer; your answer is too high
+	TooLow                       // That's not the right answer; your answer is too low
+	RateLimited                  // You gave an answer too recently
+	AlreadySolved                // You don't seem to be solving the right level, which also means the part before isn't solved yet
+)
+
+var outcomeNames = [...]string{
+	Unknown:       "unknown",
+	Correct:       "correct",
+	Wrong:         "wrong",
+	TooHigh:       "too high",
+	TooLow:        "too low",
+	RateLimited:   "rate limited",
+	AlreadySolved: "already solved",
+}
+
+func (o Outcome) String() string {
+	if o < 0 || int(o) >= len(outcomeNames) {
+		return fmt.Sprintf("Outcome(%d)", int(o))
+	}
+	return outcomeNames[o]
+}
+
+// MarshalText makes outcomes readable in the history file
+func (o Outcome) MarshalText() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+// UnmarshalText is the inverse of MarshalText
+func (o *Outcome) UnmarshalText(text []byte) error {
+	for i, name := range outcomeNames {
+		if name == string(text) {
+			*o = Outcome(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown outcome %q", text)
+}
+
+// Errors for answers we refuse to send because the history says they can't be right
+var (
+	ErrAlreadySolved = errors.New("part already solved")
+	ErrAlreadyTried  = errors.New("answer already tried")
+	ErrOutOfBounds   = errors.New("answer is outside the known bounds")
+	ErrMustWait      = errors.New("must wait before submitting again")
+)
+
+var (
+	// articleTextRegex matches the <article> holding the site's response to an answer
+	articleTextRegex = regexp.MustCompile(`(?s)<article>(.*?)</article>`)
+
+	// leftToWaitRegex matches the rate limit message e.g. "You have 1m 4s left to wait"
+	leftToWaitRegex = regexp.MustCompile(`You have (?:(\d+)m )?(\d+)s left to wait`)
+
+	// waitMinutesRegex matches the cooldown after a wrong answer e.g. "Please wait 5 minutes before trying again"
+	waitMinutesRegex = regexp.MustCompile(`(?i)wait (one|\d+) minutes? before trying again`)
+)
+
+// Result is the site's verdict on a submitted answer
+type Result struct {
+	Message string        // The site's response as plain text
+	Outcome Outcome       // What the response means
+	Wait    time.Duration // How long until we can submit again, if the site said
+}
+
+// parseResult works out what the site made of an answer from the response page
+func parseResult(page []byte) Result {
+	text := string(page)
+	if match := articleTextRegex.FindStringSubmatch(text); match != nil {
+		text = match[1]
+	}
+	text = strings.Join(strings.Fields(tagRegex.ReplaceAllString(text, "")), " ")
+
+	result := Result{Message: text}
+
+	switch {
+	case strings.Contains(text, "That's the right answer"):
+		result.Outcome = Correct
+	case strings.Contains(text, "your answer is too high"):
+		result.Outcome = TooHigh
+	case strings.Contains(text, "your answer is too low"):
+		result.Outcome = TooLow
+	case strings.Contains(text, "That's not the right answer"):
+		result.Outcome = Wrong
+	case strings.Contains(text, "You gave an answer too recently"):
+		result.Outcome = RateLimited
+	case strings.Contains(text, "You don't seem to be solving the right level"):
+		result.Outcome = AlreadySolved
+	default:
+		result.Outcome = Unknown
+	}
+
+	if match := leftToWaitRegex.FindStringSubmatch(text); match != nil {
+		minutes, _ := strconv.Atoi(match[1]) // Empty when under a minute
+		seconds, _ := strconv.Atoi(match[2])
+		result.Wait = time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second
+	} else if match := waitMinutesRegex.FindStringSubmatch(text); match != nil {
+		minutes := 1
+		if match[1] != "one" {
+			minutes, _ = strconv.Atoi(match[1])
+		}
+		result.Wait = time.Duration(minutes) * time.Minute
+	}
+
+	return result
+}
+
+// Attempt is a single recorded submission
+type Attempt struct {
+	At      time.Time     `json:"at"`
+	Answer  string        `json:"answer"`
+	Outcome Outcome       `json:"outcome"`
+	Wait    time.Duration `json:"wait,omitempty"`
+	Part    int           `json:"part"`
+}
+
+// History is every answer ever submitted for a day
+type History struct {
+	Attempts []Attempt `json:"attempts"`
+}
+
+// loadHistory returns a day's submission history from the cache, an empty one if there isn't one yet
+func loadHistory(cache *Cache, year, day int) (*History, error) {
+	raw, _, err := cache.Get(year, day, kindSubmissions)
+	if err != nil {
+		if errors.Is(err, ErrNotCached) {
+			return &History{}, nil
+		}
+		return nil, err
+	}
+
+	history := &History{}
+	if err := json.Unmarshal(raw, history); err != nil {
+		return nil, fmt.Errorf("bad submission history for day %d: %w", day, err)
+	}
+	return history, nil
+}
+
+// save writes the history back to the cache
+func (h *History) save(cache *Cache, year, day int) error {
+	raw, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = cache.Put(year, day, kindSubmissions, raw)
+	return err
+}
+
+// Solved reports whether part has been answered correctly
+//
+// AlreadySolved doesn't count, the site says the same thing when the part
+// before hasn't been solved yet
+func (h *History) Solved(part int) bool {
+	for _, a := range h.Attempts {
+		if a.Part == part && a.Outcome == Correct {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns an error if, going by the history, there's no point sending answer for part at now
+func (h *History) Check(part int, answer string, now time.Time) error {
+	if h.Solved(part) {
+		return fmt.Errorf("part %d: %w", part, ErrAlreadySolved)
+	}
+
+	for _, a := range h.Attempts {
+		if !a.At.IsZero() && a.Wait > 0 {
+			if until := a.At.Add(a.Wait); now.Before(until) {
+				return fmt.Errorf("%w: %s left", ErrMustWait, until.Sub(now).Round(time.Second))
+			}
+		}
+		// The site didn't look at answers it rate limited or sent for the wrong level
+		if a.Part == part && a.Answer == answer && a.Outcome != RateLimited && a.Outcome != Unknown && a.Outcome != AlreadySolved {
+			return fmt.Errorf("%q was %s: %w", answer, a.Outcome, ErrAlreadyTried)
+		}
+	}
+
+	n, err := strconv.ParseInt(answer, 10, 64)
+	if err != nil {
+		// Bounds only apply to numbers
+		return nil
+	}
+	low, high := h.bounds(part)
+	if high != nil && n >= *high {
+		return fmt.Errorf("%d is not less than %d which was too high: %w", n, *high, ErrOutOfBounds)
+	}
+	if low != nil && n <= *low {
+		return fmt.Errorf("%d is not more than %d which was too low: %w", n, *low, ErrOutOfBounds)
+	}
+	return nil
+}
+
+// bounds returns the highest answer to part known to be too low and the
+// lowest known to be too high, nil if there isn't one
+func (h *History) bounds(part int) (low, high *int64) {
+	for _, a := range h.Attempts {
+		if a.Part != part {
+			continue
+		}
+		tried, err := strconv.ParseInt(a.Answer, 10, 64)
+		if err != nil {
+			continue
+		}
+		switch a.Outcome {
+		case TooHigh:
+			if high == nil || tried < *high {
+				high = &tried
+			}
+		case TooLow:
+			if low == nil || tried > *low {
+				low = &tried
+			}
+		}
+	}
+	return low, high
+}
+
+// runSubmit implements the 'submit' command
+func runSubmit(args []string) error {
+	var common commonFlags
+	flags := flag.NewFlagSet("submit", flag.ContinueOnError)
+	common.register(flags)
+	force := flags.Bool("force", false, "Submit even if the local history says the answer can't be right")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	if len(args) != 3 {
+		return fmt.Errorf("submit expects 3 args 'day part answer', got: %v", args)
+	}
+	day, err := utils.ParseDay(args[0])
+	if err != nil {
+		return err
+	}
+	part, err := strconv.Atoi(args[1])
+	if err != nil || (part != 1 && part != 2) {
+		return fmt.Errorf("part should be 1 or 2, got: %s", args[1])
+	}
+
+	e, err := common.env()
+	if err != nil {
+		return err
+	}
+
+	result, err := submit(e.cache, e.connect, e.year, day, part, args[2], *force)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Day %d part %d: %s\n", day, part, result.Outcome)
+	if result.Wait > 0 {
+		fmt.Printf("Wait %s before trying again\n", result.Wait)
+	}
+	if result.Outcome == Unknown {
+		fmt.Println(result.Message)
+	}
+	return nil
+}
+
+// submit checks answer against the day's history, sends it unless it's
+// known to be wrong (or force is set) and records the outcome
+func submit(cache *Cache, connect func() (*Fetcher, error), year, day, part int, answer string, force bool) (*Result, error) {
+	answer = strings.TrimSpace(answer)
+	if answer == "" {
+		return nil, errors.New("answer must not be empty")
+	}
+
+	history, err := loadHistory(cache, year, day)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !force {
+		if err := history.Check(part, answer, now); err != nil {
+			return nil, fmt.Errorf("not submitting day %d part %d: %w", day, part, err)
+		}
+	}
+
+	fetcher, err := connect()
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := fetcher.Answer(year, day, part, answer)
+	if err != nil {
+		return nil, err
+	}
+
+	result := parseResult(page)
+
+	history.Attempts = append(history.Attempts, Attempt{
+		At:      now.UTC(),
+		Part:    part,
+		Answer:  answer,
+		Outcome: result.Outcome,
+		Wait:    result.Wait,
+	})
+	if err := history.save(cache, year, day); err != nil {
+		return nil, fmt.Errorf("could not record submission: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		name string
+		page string
+		want Result
+	}{
+		{
+			name: "correct",
+			page: "<main><article><p>That's the right answer!  You are <span>one gold star</span> closer.</p></article></main>",
+			want: Result{Outcome: Correct, Message: "That's the right answer! You are one gold star closer."},
+		},
+		{
+			name: "too high",
+			page: "<article><p>That's not the right answer; your answer is too high.  Please wait one minute before trying again.</p></article>",
+			want: Result{Outcome: TooHigh, Wait: time.Minute, Message: "That's not the right answer; your answer is too high. Please wait one minute before trying again."},
+		},
+		{
+			name: "too low",
+			page: "<article><p>That's not the right answer; your answer is too low.  Please wait 5 minutes before trying again.</p></article>",
+			want: Result{Outcome: TooLow, Wait: 5 * time.Minute, Message: "That's not the right answer; your answer is too low. Please wait 5 minutes before trying again."},
+		},
+		{
+			name: "wrong",
+			page: "<article><p>That's not the right answer.  If you're stuck, ask for hints.</p></article>",
+			want: Result{Outcome: Wrong, Message: "That's not the right answer. If you're stuck, ask for hints."},
+		},
+		{
+			name: "rate limited",
+			page: "<article><p>You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 1m 4s left to wait.</p></article>",
+			want: Result{Outcome: RateLimited, Wait: time.Minute + 4*time.Second, Message: "You gave an answer too recently; you have to wait after submitting an answer before trying again. You have 1m 4s left to wait."},
+		},
+		{
+			name: "rate limited seconds",
+			page: "<article><p>You gave an answer too recently. You have 34s left to wait.</p></article>",
+			want: Result{Outcome: RateLimited, Wait: 34 * time.Second, Message: "You gave an answer too recently. You have 34s left to wait."},
+		},
+		{
+			name: "already solved",
+			page: "<article><p>You don't seem to be solving the right level.  Did you already complete it?</p></article>",
+			want: Result{Outcome: AlreadySolved, Message: "You don't seem to be solving the right level. Did you already complete it?"},
+		},
+		{
+			name: "unknown",
+			page: "<html>Internal Server Error</html>",
+			want: Result{Outcome: Unknown, Message: "Internal Server Error"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(parseResult([]byte(tt.page)), tt.want)
+		})
+	}
+}
+
+func TestHistoryCheck(t *testing.T) {
+	now := time.Date(2020, 12, 1, 6, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+
+	history := &History{
+		Attempts: []Attempt{
+			{At: earlier, Part: 1, Answer: "500", Outcome: TooHigh, Wait: time.Minute},
+			{At: earlier, Part: 1, Answer: "600", Outcome: TooHigh, Wait: time.Minute},
+			{At: earlier, Part: 1, Answer: "100", Outcome: TooLow, Wait: time.Minute},
+			{At: earlier, Part: 1, Answer: "abc", Outcome: Wrong, Wait: time.Minute},
+			{At: earlier, Part: 2, Answer: "42", Outcome: Correct},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		part    int
+		answer  string
+		wantErr error
+	}{
+		{name: "in bounds", part: 1, answer: "250"},
+		{name: "non numeric", part: 1, answer: "xyz"},
+		{name: "too high", part: 1, answer: "550", wantErr: ErrOutOfBounds},
+		{name: "repeat", part: 1, answer: "500", wantErr: ErrAlreadyTried},
+		{name: "too low", part: 1, answer: "99", wantErr: ErrOutOfBounds},
+		{name: "already tried", part: 1, answer: "abc", wantErr: ErrAlreadyTried},
+		{name: "solved", part: 2, answer: "43", wantErr: ErrAlreadySolved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			err := history.Check(tt.part, tt.answer, now)
+			if tt.wantErr == nil {
+				is.NoErr(err)
+			} else {
+				is.True(errors.Is(err, tt.wantErr))
+			}
+		})
+	}
+}
+
+func TestHistorySolved(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2020, 12, 1, 6, 0, 0, 0, time.UTC)
+
+	// Part 2 was sent before part 1 was solved so the site said wrong level
+	history := &History{
+		Attempts: []Attempt{
+			{At: now.Add(-time.Hour), Part: 2, Answer: "42", Outcome: AlreadySolved},
+		},
+	}
+	is.True(!history.Solved(2))
+	is.NoErr(history.Check(2, "42", now)) // Can send it again once part 1 is done
+
+	history.Attempts = append(history.Attempts, Attempt{At: now, Part: 2, Answer: "42", Outcome: Correct})
+	is.True(history.Solved(2))
+}
+
+func TestHistoryCheckWait(t *testing.T) {
+	is := is.New(t)
+	now := time.Date(2020, 12, 1, 6, 0, 0, 0, time.UTC)
+
+	history := &History{
+		Attempts: []Attempt{
+			{At: now.Add(-30 * time.Second), Part: 1, Answer: "1", Outcome: RateLimited, Wait: time.Minute},
+		},
+	}
+
+	err := history.Check(1, "2", now)
+	is.True(errors.Is(err, ErrMustWait))
+
+	is.NoErr(history.Check(1, "2", now.Add(time.Minute)))
+}
+
+func TestSubmit(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+	site := newFakeSite(t)
+	site.answers[[2]int{1, 1}] = "514579"
+	connect := func() (*Fetcher, error) { return site.fetcher(t), nil }
+
+	result, err := submit(cache, connect, 2020, 1, 1, "600000", false)
+	is.NoErr(err)
+	is.Equal(result.Outcome, TooHigh)
+	is.Equal(result.Wait, time.Minute)
+
+	// We're in the cooldown now so this shouldn't get as far as the site
+	_, err = submit(cache, connect, 2020, 1, 1, "514579", false)
+	is.True(errors.Is(err, ErrMustWait))
+	is.Equal(len(site.requests), 1)
+
+	// Pretend the cooldown has passed
+	history, err := loadHistory(cache, 2020, 1)
+	is.NoErr(err)
+	history.Attempts[0].At = history.Attempts[0].At.Add(-time.Hour)
+	is.NoErr(history.save(cache, 2020, 1))
+
+	_, err = submit(cache, connect, 2020, 1, 1, "700000", false)
+	is.True(errors.Is(err, ErrOutOfBounds))
+	is.Equal(len(site.requests), 1)
+
+	result, err = submit(cache, connect, 2020, 1, 1, "514579", false)
+	is.NoErr(err)
+	is.Equal(result.Outcome, Correct)
+
+	_, err = submit(cache, connect, 2020, 1, 1, "514579", false)
+	is.True(errors.Is(err, ErrAlreadySolved))
+
+	// Forcing it goes to the site, which agrees
+	result, err = submit(cache, connect, 2020, 1, 1, "514579", true)
+	is.NoErr(err)
+	is.Equal(result.Outcome, AlreadySolved)
+
+	history, err = loadHistory(cache, 2020, 1)
+	is.NoErr(err)
+	is.Equal(len(history.Attempts), 3)
+	is.Equal(len(site.requests), 3)
+}
+
+func TestSubmitRateLimited(t *testing.T) {
+	is := is.New(t)
+	cache := NewCache(t.TempDir())
+	site := newFakeSite(t)
+	site.answers[[2]int{2, 1}] = "600"
+	site.limited = true
+	connect := func() (*Fetcher, error) { return site.fetcher(t), nil }
+
+	result, err := submit(cache, connect, 2020, 2, 1, "600", false)
+	is.NoErr(err)
+	is.Equal(result.Outcome, RateLimited)
+	is.Equal(result.Wait, time.Minute+4*time.Second)
+
+	history, err := loadHistory(cache, 2020, 2)
+	is.NoErr(err)
+	is.Equal(history.Attempts[0].Outcome, RateLimited)
+}
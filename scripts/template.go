@@ -0,0 +1,153 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	"go/format"
+	"html"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// The templates every new day is scaffolded from
+const (
+	tmplDay     = "day.go.tmpl"
+	tmplTest    = "day_test.go.tmpl"
+	tmplCommand = "main.go.tmpl"
+)
+
+// templateRoles is which template renders the file for each role
+var templateRoles = map[role]string{
+	roleCode:    tmplDay,
+	roleTest:    tmplTest,
+	roleCommand: tmplCommand,
+}
+
+// defaultTemplates are the project's own templates, used unless the user overrides them
+//
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+var (
+	// exampleRegex matches the first code block on a puzzle page, which is nearly always the example
+	exampleRegex = regexp.MustCompile(`(?s)<pre><code>(.*?)</code></pre>`)
+
+	// tagRegex matches any HTML tag, for stripping the <em>'s out of examples
+	tagRegex = regexp.MustCompile(`<[^>]*>`)
+)
+
+// dayData is everything the templates have access to
+type dayData struct {
+	Header  string // The rendered doc comment, including the trailing newline, or ""
+	Name    string // The file stem e.g. "day01", the directory is utils.Dir(Year, Day)
+	Example string // The first example input from the puzzle description, or ""
+	Year    int
+	Day     int
+}
+
+// defaultTemplateDir returns $AOC_TEMPLATES if set, otherwise 'aoc/templates'
+// under the user's config directory
+func defaultTemplateDir() string {
+	if dir, ok := os.LookupEnv("AOC_TEMPLATES"); ok && dir != "" {
+		return dir
+	}
+	base, err := os.UserConfigDir()
+	if err != nil {
+		// No config dir means no overrides, the defaults are fine
+		return ""
+	}
+	return filepath.Join(base, "aoc", "templates")
+}
+
+// loadTemplates parses the scaffolding templates, any file in dir with the
+// same name as one of the defaults is used in its place
+func loadTemplates(dir string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"rawString": rawString,
+	}
+
+	root := template.New("").Funcs(funcs)
+	for _, name := range []string{tmplDay, tmplTest, tmplCommand} {
+		src, err := readTemplate(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := root.New(name).Parse(string(src)); err != nil {
+			return nil, fmt.Errorf("could not parse template %s: %w", name, err)
+		}
+	}
+
+	return root, nil
+}
+
+// readTemplate returns the user's override for a template if there is one, or the default
+func readTemplate(dir, name string) ([]byte, error) {
+	if dir != "" {
+		src, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return src, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+	}
+	return defaultTemplates.ReadFile("templates/" + name)
+}
+
+// renderDay executes the day, test and command templates, returning gofmt'd source for each role
+func renderDay(tmpl *template.Template, data dayData) (map[role][]byte, error) {
+	sources := make(map[role][]byte, len(templateRoles))
+	for r, name := range templateRoles {
+		src, err := renderGo(tmpl, name, data)
+		if err != nil {
+			return nil, err
+		}
+		sources[r] = src
+	}
+	return sources, nil
+}
+
+// renderGo executes a single template and formats the result, so a broken
+// template is caught here rather than by the compiler later
+func renderGo(tmpl *template.Template, name string, data dayData) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("template %s did not produce valid Go: %w", name, err)
+	}
+	return src, nil
+}
+
+// extractExample returns the text of the first code block in the first part's
+// description, with any highlighting removed, or "" if there isn't one
+func extractExample(page []byte) string {
+	articles := extractArticles(page)
+	if len(articles) == 0 {
+		return ""
+	}
+	match := exampleRegex.FindStringSubmatch(articles[0])
+	if match == nil {
+		return ""
+	}
+	return html.UnescapeString(tagRegex.ReplaceAllString(match[1], ""))
+}
+
+// rawString returns s as a Go string literal, a raw one if it can be so
+// the example reads just like it does on the site
+func rawString(s string) string {
+	s = strings.TrimRight(s, "\n")
+	if strings.Contains(s, "`") || strings.Contains(s, "\r") {
+		return strconv.Quote(s)
+	}
+	return "`" + s + "`"
+}
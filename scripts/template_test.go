@@ -0,0 +1,116 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRenderDayDefaults(t *testing.T) {
+	is := is.New(t)
+
+	tmpl, err := loadTemplates(t.TempDir())
+	is.NoErr(err)
+
+	header, err := docComment(page(partOneHTML))
+	is.NoErr(err)
+
+	d := dayData{
+		Year:    2020,
+		Day:     1,
+		Name:    "day01",
+		Header:  header,
+		Example: extractExample(page(partOneHTML)),
+	}
+
+	sources, err := renderDay(tmpl, d)
+	is.NoErr(err)
+	daySrc, testSrc := sources[roleCode], sources[roleTest]
+
+	is.True(strings.HasPrefix(string(daySrc), header+"package day01\n"))
+	is.True(strings.Contains(string(daySrc), "solution.Register(2020, 1, "))
+	is.True(strings.Contains(string(testSrc), "const example = `1721\n979\n366`\n"))
+	is.True(strings.Contains(string(testSrc), "os.ReadFile(solution.InputPath(2020, 1))")) // Benchmarks read the day's input
+
+	// The test file should have the example test and a benchmark per part
+	file, err := parser.ParseFile(token.NewFileSet(), "day01_test.go", testSrc, 0)
+	is.NoErr(err)
+	is.Equal(file.Name.Name, "day01")
+	for _, want := range []string{"TestExamplePart1", "BenchmarkPart1", "BenchmarkPart2"} {
+		is.True(file.Scope.Lookup(want) != nil) // missing generated func
+	}
+
+	// And the command just hands over to the runner
+	is.True(strings.Contains(string(sources[roleCommand]), "package main\n"))
+	is.True(strings.Contains(string(sources[roleCommand]), `_ "github.com/FollowTheProcess/advent_of_code_2020/2020/day01"`))
+	is.True(strings.Contains(string(sources[roleCommand]), "solution.Main(2020, 1)"))
+}
+
+func TestRenderDayOverride(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	override := "{{ .Header }}package main\n\n// Day {{ .Day }} of {{ .Year }}\nfunc main() {}\n"
+	err := os.WriteFile(filepath.Join(dir, tmplDay), []byte(override), 0o644)
+	is.NoErr(err)
+
+	tmpl, err := loadTemplates(dir)
+	is.NoErr(err)
+
+	sources, err := renderDay(tmpl, dayData{Year: 2020, Day: 7, Name: "day07"})
+	is.NoErr(err)
+
+	is.Equal(string(sources[roleCode]), "package main\n\n// Day 7 of 2020\nfunc main() {}\n")
+	is.True(strings.Contains(string(sources[roleTest]), "func TestExamplePart1")) // Test template not overridden
+}
+
+func TestRenderDayInvalidGo(t *testing.T) {
+	is := is.New(t)
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, tmplDay), []byte("package main\n\nfunc {\n"), 0o644)
+	is.NoErr(err)
+
+	tmpl, err := loadTemplates(dir)
+	is.NoErr(err)
+
+	_, err = renderDay(tmpl, dayData{Year: 2020, Day: 7, Name: "day07"})
+	is.True(err != nil)
+}
+
+func TestExtractExample(t *testing.T) {
+	is := is.New(t)
+
+	is.Equal(extractExample(page(partOneHTML)), "1721\n979\n366\n")
+	is.Equal(extractExample(page(partTwoHTML)), "")
+
+	highlighted := `<article class="day-desc"><pre><code>1-3 a: abcde
+<em>1-3 b: cdefg</em>
+a &lt; b
+</code></pre></article>`
+	is.Equal(extractExample(page(highlighted)), "1-3 a: abcde\n1-3 b: cdefg\na < b\n")
+}
+
+func TestRawString(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "simple", in: "..##\n#..#\n", want: "`..##\n#..#`"},
+		{name: "empty", in: "", want: "``"},
+		{name: "backtick", in: "a`b", want: `"a` + "`" + `b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			is.Equal(rawString(tt.in), tt.want)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// ErrNoToken is returned when none of the token sources have a session token
+var ErrNoToken = errors.New("no session token found")
+
+// Token is a session token and where we found it
+type Token struct {
+	Value  string
+	Source string // Human readable description of where Value came from
+}
+
+// tokenSources are the places a session token can come from
+type tokenSources struct {
+	lookupEnv  func(key string) (string, bool) // os.LookupEnv outside of tests
+	flag       string                          // Value of the --session flag
+	dotEnv     string                          // Path to the project's .env file
+	configFile string                          // Path to the per user token file
+}
+
+// defaultTokenSources returns the token sources for a project rooted at root
+func defaultTokenSources(flagValue, root string) tokenSources {
+	sources := tokenSources{
+		lookupEnv: os.LookupEnv,
+		flag:      flagValue,
+		dotEnv:    filepath.Join(root, ".env"),
+	}
+	// os.UserConfigDir respects $XDG_CONFIG_HOME
+	if dir, err := os.UserConfigDir(); err == nil {
+		sources.configFile = filepath.Join(dir, "aoc", "token")
+	}
+	return sources
+}
+
+// resolve returns the first token it finds looking, in order, at the
+// --session flag, $AOC_SESSION, AOC_SESSION in the .env file and finally
+// the per user token file
+func (s tokenSources) resolve() (Token, error) {
+	if s.flag != "" {
+		return checkedToken(s.flag, "--session flag")
+	}
+
+	if value, ok := s.lookupEnv("AOC_SESSION"); ok && value != "" {
+		return checkedToken(value, "AOC_SESSION environment variable")
+	}
+
+	if s.dotEnv != "" {
+		env, err := godotenv.Read(s.dotEnv)
+		switch {
+		case err == nil:
+			if value := env["AOC_SESSION"]; value != "" {
+				return checkedToken(value, "AOC_SESSION in "+s.dotEnv)
+			}
+		case errors.Is(err, fs.ErrNotExist):
+			// No .env is fine, plenty of people don't use one
+		default:
+			return Token{}, fmt.Errorf("could not read %s: %w", s.dotEnv, err)
+		}
+	}
+
+	if s.configFile != "" {
+		raw, err := os.ReadFile(s.configFile)
+		switch {
+		case err == nil:
+			if value := strings.TrimSpace(string(raw)); value != "" {
+				return checkedToken(value, s.configFile)
+			}
+		case errors.Is(err, fs.ErrNotExist):
+		default:
+			return Token{}, fmt.Errorf("could not read %s: %w", s.configFile, err)
+		}
+	}
+
+	return Token{}, fmt.Errorf(
+		"%w: pass --session, set AOC_SESSION in the environment or %s, or put it in %s",
+		ErrNoToken, s.dotEnv, s.configFile,
+	)
+}
+
+// checkedToken returns a Token if value looks like a session token, which is always hex
+func checkedToken(value, source string) (Token, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return Token{}, fmt.Errorf("session token from %s is empty", source)
+	}
+	for _, char := range value {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", char) {
+			return Token{}, fmt.Errorf("session token from %s is not valid hex, it should be the value of the 'session' cookie", source)
+		}
+	}
+	return Token{Value: value, Source: source}, nil
+}
+
+// masked returns the token with all but the first and last few characters hidden
+func (t Token) masked() string {
+	if len(t.Value) <= 8 {
+		return strings.Repeat("*", len(t.Value))
+	}
+	return t.Value[:4] + strings.Repeat("*", len(t.Value)-8) + t.Value[len(t.Value)-4:]
+}
+
+// CheckToken confirms the session is logged in by asking for the settings page,
+// which redirects to the login page for anyone who isn't
+func (f *Fetcher) CheckToken() error {
+	client := *f.Client
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	shallow := *f
+	shallow.Client = &client
+	shallow.Retries = 0
+	_, err := shallow.do(http.MethodGet, "/settings", nil)
+	return err
+}
+
+// runToken implements the 'token' command
+func runToken(args []string) error {
+	if len(args) < 1 || args[0] != "check" {
+		return fmt.Errorf("token expects a subcommand 'check', got: %v", args)
+	}
+
+	var common commonFlags
+	flags := flag.NewFlagSet("token check", flag.ContinueOnError)
+	common.register(flags)
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	e, err := common.env()
+	if err != nil {
+		return err
+	}
+
+	token, err := e.tokens.resolve()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Using session token %s from %s\n", token.masked(), token.Source)
+
+	fetcher, err := NewFetcher(e.config.BaseURL, token.Value, e.config.Timeout)
+	if err != nil {
+		return err
+	}
+	if err := fetcher.CheckToken(); err != nil {
+		return fmt.Errorf("session token from %s is not valid: %w", token.Source, err)
+	}
+
+	fmt.Println("Token is valid")
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestTokenSourcesResolve(t *testing.T) {
+	dir := t.TempDir()
+
+	dotEnv := filepath.Join(dir, ".env")
+	if err := os.WriteFile(dotEnv, []byte("AOC_SESSION=dddd\nOTHER=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	configFile := filepath.Join(dir, "token")
+	if err := os.WriteFile(configFile, []byte("cccc\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	missing := filepath.Join(dir, "missing")
+
+	withEnv := func(key string) (string, bool) {
+		if key == "AOC_SESSION" {
+			return "eeee", true
+		}
+		return "", false
+	}
+	noEnv := func(string) (string, bool) { return "", false }
+
+	tests := []struct {
+		name    string
+		sources tokenSources
+		want    Token
+		wantErr error
+	}{
+		{
+			name:    "flag beats everything",
+			sources: tokenSources{flag: "ffff", lookupEnv: withEnv, dotEnv: dotEnv, configFile: configFile},
+			want:    Token{Value: "ffff", Source: "--session flag"},
+		},
+		{
+			name:    "env beats files",
+			sources: tokenSources{lookupEnv: withEnv, dotEnv: dotEnv, configFile: configFile},
+			want:    Token{Value: "eeee", Source: "AOC_SESSION environment variable"},
+		},
+		{
+			name:    ".env beats config",
+			sources: tokenSources{lookupEnv: noEnv, dotEnv: dotEnv, configFile: configFile},
+			want:    Token{Value: "dddd", Source: "AOC_SESSION in " + dotEnv},
+		},
+		{
+			name:    "missing .env is fine",
+			sources: tokenSources{lookupEnv: noEnv, dotEnv: missing, configFile: configFile},
+			want:    Token{Value: "cccc", Source: configFile},
+		},
+		{
+			name:    "nothing anywhere",
+			sources: tokenSources{lookupEnv: noEnv, dotEnv: missing, configFile: missing},
+			wantErr: ErrNoToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			got, err := tt.sources.resolve()
+			if tt.wantErr != nil {
+				is.True(errors.Is(err, tt.wantErr))
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
+
+func TestCheckedToken(t *testing.T) {
+	is := is.New(t)
+
+	got, err := checkedToken("  53616c7465645f5f\n", "test")
+	is.NoErr(err)
+	is.Equal(got.Value, "53616c7465645f5f")
+
+	_, err = checkedToken("session=53616c7465645f5f", "test") // Pasted the whole cookie
+	is.True(err != nil)
+
+	_, err = checkedToken("   ", "test")
+	is.True(err != nil)
+}
+
+func TestTokenMasked(t *testing.T) {
+	is := is.New(t)
+	is.Equal(Token{Value: "53616c7465645f5f"}.masked(), "5361********5f5f")
+	is.Equal(Token{Value: "abc"}.masked(), "***")
+}
+
+func TestFetcherCheckToken(t *testing.T) {
+	is := is.New(t)
+	site := newFakeSite(t)
+
+	f := site.fetcher(t)
+	is.NoErr(f.CheckToken())
+
+	f.Session = "deadbeef"
+	err := f.CheckToken()
+	is.True(errors.Is(err, ErrUnauthorized))
+}
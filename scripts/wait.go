@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"time"
+
+	// Puzzles unlock on New York time, don't rely on the system having a tz database
+	_ "time/tzdata"
+)
+
+const (
+	WAITWINDOW  = 12 * time.Hour  // Default for how far ahead of an unlock we're prepared to wait
+	WAITJITTER  = 3 * time.Second // Default upper bound on the polite random delay after unlocking
+	UNLOCKPOLL  = 2 * time.Second // How often to retry if the site hasn't caught up with the clock
+	UNLOCKTRIES = 10              // How many times to try before giving up on it
+	TICK        = 1 * time.Second // How often the countdown updates
+	EASTERN     = "America/New_York"
+)
+
+// ErrTooEarly is returned when asked to wait for a puzzle that unlocks further away than the wait window
+var ErrTooEarly = errors.New("puzzle unlocks too far in the future to wait for")
+
+// clock is the bits of the time package we need, so tests don't have to wait for midnight
+type clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+// realClock is a clock that tells the actual time
+type realClock struct{}
+
+func (realClock) Now() time.Time                         { return time.Now() }
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// unlockTime returns when a day's puzzle unlocks, midnight in New York on that day of December
+func unlockTime(year, day int) time.Time {
+	loc, err := time.LoadLocation(EASTERN)
+	if err != nil {
+		// Can't happen with time/tzdata embedded, but December is always EST
+		loc = time.FixedZone("EST", -5*60*60)
+	}
+	return time.Date(year, time.December, day, 0, 0, 0, 0, loc)
+}
+
+// waiter waits for puzzles to unlock
+type waiter struct {
+	clock  clock
+	out    io.Writer            // Where the countdown goes
+	jitter func() time.Duration // The polite delay after unlocking
+	window time.Duration        // Longest we'll agree to wait
+}
+
+// newWaiter returns a waiter on the real clock, counting down to out
+func newWaiter(out io.Writer, window, maxJitter time.Duration) *waiter {
+	return &waiter{
+		clock:  realClock{},
+		out:    out,
+		window: window,
+		jitter: func() time.Duration {
+			if maxJitter <= 0 {
+				return 0
+			}
+			return time.Duration(rand.Int63n(int64(maxJitter)))
+		},
+	}
+}
+
+// wait blocks until day's puzzle has unlocked (plus a little jitter so the
+// whole world doesn't arrive in the same millisecond), showing a countdown
+//
+// It returns straight away if the puzzle is already unlocked and ErrTooEarly
+// if it's further away than the window
+func (w *waiter) wait(year, day int) error {
+	unlock := unlockTime(year, day)
+	remaining := unlock.Sub(w.clock.Now())
+	if remaining <= 0 {
+		return nil
+	}
+	if remaining > w.window {
+		return fmt.Errorf("%d day %d unlocks in %s, more than the %s wait window: %w",
+			year, day, remaining.Round(time.Second), w.window, ErrTooEarly)
+	}
+
+	for remaining > 0 {
+		fmt.Fprintf(w.out, "\rDay %d unlocks in %s ", day, countdown(remaining))
+		tick := TICK
+		if remaining < tick {
+			tick = remaining
+		}
+		<-w.clock.After(tick)
+		remaining = unlock.Sub(w.clock.Now())
+	}
+
+	jitter := w.jitter()
+	fmt.Fprintf(w.out, "\rDay %d unlocked, fetching in %s\n", day, jitter.Round(time.Millisecond))
+	<-w.clock.After(jitter)
+
+	return nil
+}
+
+// untilUnlocked calls fn until it stops failing with ErrNotUnlocked, as the
+// site's clock and ours won't agree to the millisecond
+func (w *waiter) untilUnlocked(fn func() error) error {
+	var err error
+	for try := 0; try < UNLOCKTRIES; try++ {
+		err = fn()
+		if !errors.Is(err, ErrNotUnlocked) {
+			return err
+		}
+		<-w.clock.After(UNLOCKPOLL)
+	}
+	return err
+}
+
+// countdown formats a duration as hh:mm:ss
+func countdown(d time.Duration) string {
+	d = d.Round(time.Second)
+	h := d / time.Hour
+	d -= h * time.Hour
+	m := d / time.Minute
+	d -= m * time.Minute
+	s := d / time.Second
+	return fmt.Sprintf("%02d:%02d:%02d", h, m, s)
+}
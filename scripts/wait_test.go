@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matryer/is"
+)
+
+// fakeClock is a clock where time only passes when someone waits on it
+type fakeClock struct {
+	now    time.Time
+	waited time.Duration
+}
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func (f *fakeClock) After(d time.Duration) <-chan time.Time {
+	f.now = f.now.Add(d)
+	f.waited += d
+	ch := make(chan time.Time, 1)
+	ch <- f.now
+	return ch
+}
+
+func testWaiter(now time.Time, jitter time.Duration) (*waiter, *fakeClock, *bytes.Buffer) {
+	clk := &fakeClock{now: now}
+	out := &bytes.Buffer{}
+	w := &waiter{
+		clock:  clk,
+		out:    out,
+		window: time.Hour,
+		jitter: func() time.Duration { return jitter },
+	}
+	return w, clk, out
+}
+
+func TestUnlockTime(t *testing.T) {
+	is := is.New(t)
+	// Midnight EST is 5am UTC
+	is.True(unlockTime(2020, 1).Equal(time.Date(2020, time.December, 1, 5, 0, 0, 0, time.UTC)))
+	is.True(unlockTime(2020, 25).Equal(time.Date(2020, time.December, 25, 5, 0, 0, 0, time.UTC)))
+}
+
+func TestWaitCountsDown(t *testing.T) {
+	is := is.New(t)
+	unlock := unlockTime(2020, 6)
+	w, clk, out := testWaiter(unlock.Add(-3*time.Second-500*time.Millisecond), 750*time.Millisecond)
+
+	is.NoErr(w.wait(2020, 6))
+
+	is.True(!clk.now.Before(unlock.Add(750 * time.Millisecond))) // Waited out the unlock and the jitter
+	is.Equal(clk.waited, 3*time.Second+500*time.Millisecond+750*time.Millisecond)
+
+	got := out.String()
+	is.True(strings.Contains(got, "\rDay 6 unlocks in 00:00:04 ")) // 3.5s rounds up
+	is.True(strings.Contains(got, "\rDay 6 unlocks in 00:00:01 "))
+	is.True(strings.HasSuffix(got, "\rDay 6 unlocked, fetching in 750ms\n"))
+}
+
+func TestWaitAlreadyUnlocked(t *testing.T) {
+	is := is.New(t)
+	w, clk, out := testWaiter(unlockTime(2020, 6).Add(time.Minute), time.Second)
+
+	is.NoErr(w.wait(2020, 6))
+	is.Equal(clk.waited, time.Duration(0))
+	is.Equal(out.Len(), 0)
+}
+
+func TestWaitTooEarly(t *testing.T) {
+	is := is.New(t)
+	w, clk, _ := testWaiter(unlockTime(2020, 6).Add(-2*time.Hour), time.Second)
+
+	err := w.wait(2020, 6)
+	is.True(errors.Is(err, ErrTooEarly))
+	is.Equal(clk.waited, time.Duration(0))
+}
+
+func TestUntilUnlocked(t *testing.T) {
+	is := is.New(t)
+	w, clk, _ := testWaiter(time.Now(), 0)
+
+	calls := 0
+	err := w.untilUnlocked(func() error {
+		calls++
+		if calls < 3 {
+			return ErrNotUnlocked
+		}
+		return nil
+	})
+	is.NoErr(err)
+	is.Equal(calls, 3)
+	is.Equal(clk.waited, 2*UNLOCKPOLL)
+
+	// Other errors aren't retried
+	calls = 0
+	err = w.untilUnlocked(func() error {
+		calls++
+		return ErrUnauthorized
+	})
+	is.True(errors.Is(err, ErrUnauthorized))
+	is.Equal(calls, 1)
+}
+
+func TestCountdown(t *testing.T) {
+	is := is.New(t)
+	is.Equal(countdown(0), "00:00:00")
+	is.Equal(countdown(90*time.Second), "00:01:30")
+	is.Equal(countdown(11*time.Hour+59*time.Minute+59*time.Second), "11:59:59")
+}
@@ -0,0 +1,133 @@
+package solution
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// ErrNoAnswers is returned when a day has no recorded answers yet
+var ErrNoAnswers = errors.New("no answers recorded")
+
+// Answers are the known right answers to a day, for the input they were recorded against
+type Answers struct {
+	Input string `json:"input"`           // HashInput of the input
+	Part1 string `json:"part1,omitempty"` // "" if not known yet
+	Part2 string `json:"part2,omitempty"`
+}
+
+// part returns the recorded answer to part n
+func (a Answers) part(n int) string {
+	if n == 1 {
+		return a.Part1
+	}
+	return a.Part2
+}
+
+// HashInput returns the hash answers are tied to for input
+func HashInput(input []byte) string {
+	sum := sha256.Sum256(input)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// AnswersPath returns where year's day keeps its answers
+func AnswersPath(year, day int) string {
+	return filepath.Join(utils.Dir(year, day), "answers.json")
+}
+
+// LoadAnswers returns the recorded answers for year's day
+func LoadAnswers(year, day int) (Answers, error) {
+	data, err := os.ReadFile(AnswersPath(year, day))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return Answers{}, fmt.Errorf("%d day %d: %w", year, day, ErrNoAnswers)
+		}
+		return Answers{}, err
+	}
+
+	var answers Answers
+	if err := json.Unmarshal(data, &answers); err != nil {
+		return Answers{}, fmt.Errorf("%s: %w", AnswersPath(year, day), err)
+	}
+	return answers, nil
+}
+
+// SaveAnswers records answers for year's day, replacing any already there
+func SaveAnswers(year, day int, answers Answers) error {
+	data, err := json.MarshalIndent(answers, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(AnswersPath(year, day), append(data, '\n'), 0o644)
+}
+
+// Answers solves both parts on input, returning answers ready to record
+func (p Puzzle) Answers(ctx context.Context, input []byte) (Answers, error) {
+	got := Answers{Input: HashInput(input)}
+	for _, result := range p.Run(ctx, bytes.NewReader(input), 0) {
+		if result.Err != nil {
+			return Answers{}, result.Err
+		}
+		if result.Part == 1 {
+			got.Part1 = result.Answer.String()
+		} else {
+			got.Part2 = result.Answer.String()
+		}
+	}
+	return got, nil
+}
+
+// MismatchError is returned when a day's answers aren't what was recorded
+type MismatchError struct {
+	Want Answers
+	Got  Answers
+	Year int
+	Day  int
+}
+
+func (e *MismatchError) Error() string {
+	var b strings.Builder
+	if e.Want.Input != e.Got.Input {
+		fmt.Fprintf(&b, "%d day %d: input doesn't match the one the answers were recorded for\n", e.Year, e.Day)
+		fmt.Fprintf(&b, "  want: %s\n  got:  %s\n", e.Want.Input, e.Got.Input)
+		fmt.Fprintf(&b, "if the new input is right, record its answers with 'aoc verify --update %d'", e.Day)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "%d day %d: answers have changed", e.Year, e.Day)
+	for n := 1; n <= 2; n++ {
+		want, got := e.Want.part(n), e.Got.part(n)
+		if want != "" && want != got {
+			fmt.Fprintf(&b, "\n  part %d: want %s, got %s", n, want, got)
+		}
+	}
+	return b.String()
+}
+
+// Verify solves year's day on input and checks the answers against want, parts
+// with no recorded answer are not checked, a mismatch is a *MismatchError
+func (p Puzzle) Verify(ctx context.Context, input []byte, want Answers) error {
+	if hash := HashInput(input); hash != want.Input {
+		// No point solving it, the answers won't be for this input
+		return &MismatchError{Year: p.Year, Day: p.Day, Want: want, Got: Answers{Input: hash}}
+	}
+
+	got, err := p.Answers(ctx, input)
+	if err != nil {
+		return err
+	}
+	if (want.Part1 != "" && want.Part1 != got.Part1) || (want.Part2 != "" && want.Part2 != got.Part2) {
+		return &MismatchError{Year: p.Year, Day: p.Day, Want: want, Got: got}
+	}
+	return nil
+}
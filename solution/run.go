@@ -0,0 +1,224 @@
+package solution
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+
+	"github.com/FollowTheProcess/advent_of_code_2020/utils"
+)
+
+// STDIN is the input path that means read from standard input
+const STDIN = "-"
+
+// InputPath returns where year's day keeps its puzzle input
+func InputPath(year, day int) string {
+	return filepath.Join(utils.Dir(year, day), fmt.Sprintf("day%02d.txt", day))
+}
+
+// ExamplePath returns where year's day keeps the nth example from its puzzle description
+func ExamplePath(year, day, n int) string {
+	return filepath.Join(utils.Dir(year, day), "testdata", fmt.Sprintf("example%d.txt", n))
+}
+
+// Source is where a day's input comes from, the zero value is the day's puzzle input
+type Source struct {
+	Path    string // A file to read instead, or STDIN
+	Example int    // Use this stored example instead, counting from 1
+}
+
+// RegisterFlags adds --input and --example to flags, setting s
+func (s *Source) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&s.Path, "input", "", "Read the input from this file, or '-' for stdin (default the day's puzzle input)")
+	flags.IntVar(&s.Example, "example", 0, "Use the day's nth stored example as the input")
+}
+
+// Validate checks the source makes sense before anything is opened
+func (s Source) Validate() error {
+	if s.Path != "" && s.Example != 0 {
+		return errors.New("--input and --example can't be used together")
+	}
+	if s.Example < 0 {
+		return fmt.Errorf("example should be 1 or more, got: %d", s.Example)
+	}
+	return nil
+}
+
+// Open returns the input for year's day, the caller should close it
+func (s Source) Open(year, day int) (io.ReadCloser, error) {
+	if err := s.Validate(); err != nil {
+		return nil, err
+	}
+
+	var path string
+	switch {
+	case s.Path == STDIN:
+		return io.NopCloser(os.Stdin), nil
+	case s.Path != "":
+		path = s.Path
+	case s.Example != 0:
+		path = ExamplePath(year, day, s.Example)
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("day %d has no example %d, save it as %s", day, s.Example, path)
+		}
+	default:
+		path = InputPath(year, day)
+	}
+	return os.Open(path)
+}
+
+// Result is the outcome of running one part of a day
+type Result struct {
+	Err      error // Why there's no answer, if there isn't
+	Answer   Answer
+	Year     int
+	Day      int
+	Part     int
+	Duration time.Duration // How long the part took, not counting parsing
+}
+
+// Parts returns the parts part refers to, 0 means both
+func Parts(part int) []int {
+	if part == 0 {
+		return []int{1, 2}
+	}
+	return []int{part}
+}
+
+// Failed returns a result for each part of year's day, all failing with err
+func Failed(year, day, part int, err error) []Result {
+	var results []Result
+	for _, n := range Parts(part) {
+		results = append(results, Result{Year: year, Day: day, Part: n, Err: err})
+	}
+	return results
+}
+
+// Hook is called around solving each part and must call solve exactly once,
+// it's for things like profiling that should only see the part itself
+type Hook func(part int, solve func())
+
+// Run parses input with a fresh solution and solves each part, part 0 means both
+//
+// If ctx is done before the solution returns, Run stops waiting for it and
+// the part fails with ctx.Err() so a solution that never checks ctx can't hold
+// up the caller
+func (p Puzzle) Run(ctx context.Context, input io.Reader, part int) []Result {
+	return p.RunHook(ctx, input, part, nil)
+}
+
+// RunHook is Run calling hook around each part, a nil hook does nothing
+func (p Puzzle) RunHook(ctx context.Context, input io.Reader, part int, hook Hook) []Result {
+	s := p.New()
+	err := wait(ctx, func() error { return s.Parse(ctx, input) })
+	if err != nil {
+		return Failed(p.Year, p.Day, part, fmt.Errorf("day %d: parse: %w", p.Day, err))
+	}
+	if hook == nil {
+		hook = func(_ int, solve func()) { solve() }
+	}
+
+	solvers := map[int]func(context.Context) (Answer, error){1: s.Part1, 2: s.Part2}
+	var results []Result
+	for _, n := range Parts(part) {
+		solvePart := solvers[n]
+		result := Result{Year: p.Year, Day: p.Day, Part: n}
+		hook(n, func() {
+			// Only read answer if the part finished, otherwise it's still running
+			var answer Answer
+			start := time.Now()
+			err := wait(ctx, func() (err error) {
+				answer, err = solvePart(ctx)
+				return err
+			})
+			result.Duration = time.Since(start)
+			if err != nil {
+				result.Err = fmt.Errorf("day %d part %d: %w", p.Day, n, err)
+				return
+			}
+			result.Answer = answer
+		})
+		results = append(results, result)
+	}
+	return results
+}
+
+// Solve is Run writing the answers to w, it returns the first error if any part failed
+func (p Puzzle) Solve(ctx context.Context, w io.Writer, input io.Reader, part int) error {
+	var first error
+	for _, result := range p.Run(ctx, input, part) {
+		if result.Err != nil {
+			if first == nil {
+				first = result.Err
+			}
+			continue
+		}
+		fmt.Fprintf(w, "Day %d part %d: %s\n", result.Day, result.Part, result.Answer)
+	}
+	return first
+}
+
+// wait runs fn in the background and waits for it or for ctx to be done,
+// whichever is first, in the second case fn is left to finish on its own
+func wait(ctx context.Context, fn func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	done := make(chan error, 1)
+	go func() { done <- fn() }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SolveFrom is Solve with the input opened from src
+func (p Puzzle) SolveFrom(ctx context.Context, w io.Writer, src Source, part int) error {
+	input, err := src.Open(p.Year, p.Day)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+	return p.Solve(ctx, w, input, part)
+}
+
+// Main is all a day's own command needs to do, it solves year's day and
+// prints the answers, exiting non-zero if anything fails
+//
+// It takes the same --input and --example flags as the runner and stops on Ctrl-C
+func Main(year, day int) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := run(ctx, os.Stdout, os.Args[1:], year, day)
+	stop()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// run is Main without the exit
+func run(ctx context.Context, w io.Writer, args []string, year, day int) error {
+	var src Source
+	flags := flag.NewFlagSet(fmt.Sprintf("day%02d", day), flag.ContinueOnError)
+	src.RegisterFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected args: %v", flags.Args())
+	}
+
+	puzzle, err := Get(year, day)
+	if err != nil {
+		return err
+	}
+	return puzzle.SolveFrom(ctx, w, src, 0)
+}
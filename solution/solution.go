@@ -0,0 +1,150 @@
+// Package solution defines what a day's solution looks like and keeps a
+// registry of every day so one runner can find and run them all
+//
+// Each day registers itself from an init function:
+//
+//	func init() {
+//		solution.Register(2020, 1, func() solution.Solution { return &puzzle{} })
+//	}
+//
+// and is linked into the runner with a blank import
+package solution
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// ErrNotRegistered is returned when asked for a day nobody has registered
+var ErrNotRegistered = errors.New("no solution registered")
+
+// Solution is one day's puzzle, Parse is always called before either part
+//
+// Anything that can take a while should give up once ctx is done, the runner
+// stops waiting at that point either way
+type Solution interface {
+	// Parse reads the puzzle input from r, anything both parts need should be kept on the solution
+	Parse(ctx context.Context, r io.Reader) error
+
+	// Part1 returns the answer to part 1 of the puzzle
+	Part1(ctx context.Context) (Answer, error)
+
+	// Part2 returns the answer to part 2 of the puzzle
+	Part2(ctx context.Context) (Answer, error)
+}
+
+// Answer is the answer to one part of a puzzle, most are numbers but some are text
+type Answer struct {
+	text  string
+	n     int
+	isInt bool
+}
+
+// Int returns a numeric answer
+func Int(n int) Answer {
+	return Answer{n: n, isInt: true}
+}
+
+// Text returns a text answer
+func Text(s string) Answer {
+	return Answer{text: s}
+}
+
+// String returns the answer as it would be submitted
+func (a Answer) String() string {
+	if a.isInt {
+		return strconv.Itoa(a.n)
+	}
+	return a.text
+}
+
+// Int returns a numeric answer's value, ok is false for text answers
+func (a Answer) Int() (n int, ok bool) {
+	return a.n, a.isInt
+}
+
+// Puzzle is a registered day
+type Puzzle struct {
+	New  func() Solution // Returns a fresh solution ready to Parse
+	Year int
+	Day  int
+}
+
+// key identifies a day in the registry
+type key struct {
+	year int
+	day  int
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[key]Puzzle)
+)
+
+// Register makes a day's solution available to the runner, fn is called
+// once per run to get a fresh solution
+//
+// It panics if the day is out of range or registered twice, like
+// database/sql.Register does for drivers
+func Register(year, day int, fn func() Solution) {
+	if day < 1 || day > 25 {
+		panic(fmt.Sprintf("solution: register %d day %d: day should be between 1 and 25", year, day))
+	}
+	if fn == nil {
+		panic(fmt.Sprintf("solution: register %d day %d: nil constructor", year, day))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	k := key{year: year, day: day}
+	if _, dup := registry[k]; dup {
+		panic(fmt.Sprintf("solution: register %d day %d: registered twice", year, day))
+	}
+	registry[k] = Puzzle{Year: year, Day: day, New: fn}
+}
+
+// Get returns the registered solution for year's day
+func Get(year, day int) (Puzzle, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	puzzle, ok := registry[key{year: year, day: day}]
+	if !ok {
+		return Puzzle{}, fmt.Errorf("%d day %d: %w", year, day, ErrNotRegistered)
+	}
+	return puzzle, nil
+}
+
+// Days returns every registered day of year in order
+func Days(year int) []int {
+	mu.RLock()
+	defer mu.RUnlock()
+	var days []int
+	for k := range registry {
+		if k.year == year {
+			days = append(days, k.day)
+		}
+	}
+	sort.Ints(days)
+	return days
+}
+
+// Years returns every year with a registered day in order
+func Years() []int {
+	mu.RLock()
+	defer mu.RUnlock()
+	seen := make(map[int]bool)
+	var years []int
+	for k := range registry {
+		if !seen[k.year] {
+			seen[k.year] = true
+			years = append(years, k.year)
+		}
+	}
+	sort.Ints(years)
+	return years
+}
@@ -0,0 +1,182 @@
+package solution
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+type fake struct{}
+
+func (fake) Parse(context.Context, io.Reader) error { return nil }
+func (fake) Part1(context.Context) (Answer, error)  { return Int(1), nil }
+func (fake) Part2(context.Context) (Answer, error)  { return Text("two"), nil }
+func newFake() Solution                             { return fake{} }
+
+// mustPanic fails the test if fn doesn't panic
+func mustPanic(t *testing.T, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Fatal("did not panic")
+		}
+	}()
+	fn()
+}
+
+func TestRegistry(t *testing.T) {
+	is := is.New(t)
+
+	Register(1999, 3, newFake)
+	Register(1999, 1, newFake)
+
+	puzzle, err := Get(1999, 3)
+	is.NoErr(err)
+	is.Equal(puzzle.Year, 1999)
+	is.Equal(puzzle.Day, 3)
+
+	_, err = Get(1999, 2)
+	is.True(errors.Is(err, ErrNotRegistered))
+
+	is.Equal(Days(1999), []int{1, 3}) // In order
+	is.Equal(len(Days(1998)), 0)
+	is.Equal(Years(), []int{1999})
+
+	mustPanic(t, func() { Register(1999, 3, newFake) })
+	mustPanic(t, func() { Register(1999, 26, newFake) })
+	mustPanic(t, func() { Register(1999, 4, nil) })
+}
+
+func TestAnswer(t *testing.T) {
+	is := is.New(t)
+
+	n, ok := Int(42).Int()
+	is.True(ok)
+	is.Equal(n, 42)
+	is.Equal(Int(42).String(), "42")
+
+	_, ok = Text("ABC").Int()
+	is.True(!ok)
+	is.Equal(Text("ABC").String(), "ABC")
+}
+
+// lines is a solution that answers with the number of lines and the first one
+type lines struct {
+	lines []string
+}
+
+func (l *lines) Parse(_ context.Context, r io.Reader) error {
+	input, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	l.lines = strings.Fields(string(input))
+	if len(l.lines) == 0 {
+		return errors.New("empty")
+	}
+	return nil
+}
+
+func (l *lines) Part1(_ context.Context) (Answer, error) { return Int(len(l.lines)), nil }
+func (l *lines) Part2(_ context.Context) (Answer, error) { return Text(l.lines[0]), nil }
+
+func TestSolve(t *testing.T) {
+	is := is.New(t)
+	puzzle := Puzzle{Year: 1999, Day: 7, New: func() Solution { return &lines{} }}
+
+	buf := &bytes.Buffer{}
+	is.NoErr(puzzle.Solve(context.Background(), buf, strings.NewReader("abc\ndef\n"), 0))
+	is.Equal(buf.String(), "Day 7 part 1: 2\nDay 7 part 2: abc\n")
+
+	buf.Reset()
+	is.NoErr(puzzle.Solve(context.Background(), buf, strings.NewReader("abc\ndef\n"), 2))
+	is.Equal(buf.String(), "Day 7 part 2: abc\n")
+
+	err := puzzle.Solve(context.Background(), buf, strings.NewReader(""), 0)
+	is.True(err != nil)
+	is.True(strings.Contains(err.Error(), "day 7: parse: empty"))
+}
+
+func TestSource(t *testing.T) {
+	is := is.New(t)
+	other := filepath.Join(t.TempDir(), "other.txt")
+	is.NoErr(os.WriteFile(other, []byte("x\ny\n"), 0o644))
+
+	puzzle := Puzzle{Year: 1999, Day: 8, New: func() Solution { return &lines{} }}
+	buf := &bytes.Buffer{}
+	is.NoErr(puzzle.SolveFrom(context.Background(), buf, Source{Path: other}, 1))
+	is.Equal(buf.String(), "Day 8 part 1: 2\n")
+
+	_, err := Source{Example: 1}.Open(1999, 8)
+	is.True(err != nil) // Nothing stored for 1999
+	is.True(strings.Contains(err.Error(), "day 8 has no example 1"))
+
+	is.True(Source{Path: other, Example: 1}.Validate() != nil) // Can't have both
+	is.True(Source{Example: -1}.Validate() != nil)
+	is.NoErr(Source{Path: STDIN}.Validate())
+}
+
+func TestPaths(t *testing.T) {
+	is := is.New(t)
+	is.Equal(filepath.Base(InputPath(2020, 3)), "day03.txt")
+	is.Equal(ExamplePath(2020, 3, 2), filepath.Join(filepath.Dir(InputPath(2020, 3)), "testdata", "example2.txt"))
+}
+
+func TestVerify(t *testing.T) {
+	is := is.New(t)
+	puzzle := Puzzle{Year: 1999, Day: 7, New: func() Solution { return &lines{} }}
+	input := []byte("abc\ndef\n")
+
+	got, err := puzzle.Answers(context.Background(), input)
+	is.NoErr(err)
+	is.Equal(got.Input, HashInput(input))
+	is.Equal(got.Part1, "2")
+	is.Equal(got.Part2, "abc")
+	is.NoErr(puzzle.Verify(context.Background(), input, got))
+
+	// Part 2 isn't known yet
+	is.NoErr(puzzle.Verify(context.Background(), input, Answers{Input: got.Input, Part1: "2"}))
+
+	err = puzzle.Verify(context.Background(), input, Answers{Input: got.Input, Part1: "3", Part2: "abc"})
+	var mismatch *MismatchError
+	is.True(errors.As(err, &mismatch))
+	is.Equal(err.Error(), "1999 day 7: answers have changed\n  part 1: want 3, got 2")
+
+	err = puzzle.Verify(context.Background(), []byte("xyz\n"), got)
+	is.True(errors.As(err, &mismatch))
+	is.True(strings.Contains(err.Error(), "input doesn't match"))
+	is.True(strings.Contains(err.Error(), "aoc verify --update 7"))
+
+	_, err = LoadAnswers(1999, 7)
+	is.True(errors.Is(err, ErrNoAnswers))
+}
+
+func TestRunHook(t *testing.T) {
+	is := is.New(t)
+	puzzle := Puzzle{Year: 1999, Day: 7, New: func() Solution { return &lines{} }}
+
+	var parts []int
+	results := puzzle.RunHook(context.Background(), strings.NewReader("abc\n"), 0, func(part int, solve func()) {
+		parts = append(parts, part)
+		solve()
+	})
+	is.Equal(parts, []int{1, 2})
+	is.Equal(len(results), 2)
+	is.Equal(results[1].Answer.String(), "abc")
+
+	// Parsing failed so there's nothing to hook
+	parts = nil
+	results = puzzle.RunHook(context.Background(), strings.NewReader(""), 1, func(part int, solve func()) {
+		parts = append(parts, part)
+		solve()
+	})
+	is.Equal(len(parts), 0)
+	is.Equal(results[0].Err.Error(), "day 7: parse: empty")
+}
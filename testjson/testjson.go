@@ -0,0 +1,35 @@
+// Package testjson reads the stream of events printed by `go test -json`
+package testjson
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+)
+
+// Event is the bit of a `go test -json` event the tools care about
+type Event struct {
+	Action  string // run, pass, fail, skip, output etc.
+	Package string // Import path of the package being tested
+	Test    string // "" for events about the whole package
+	Output  string // What the test printed, for output events
+}
+
+// Read calls fn with each event about a single test in r
+//
+// Package events are dropped, as is anything that isn't an event, like build
+// failures that come through as plain text
+func Read(r io.Reader, fn func(event Event)) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		var event Event
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if event.Test == "" {
+			continue
+		}
+		fn(event)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,29 @@
+package testjson
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestRead(t *testing.T) {
+	is := is.New(t)
+	stream := `{"Action":"run","Package":"example/day01","Test":"TestPart1"}
+{"Action":"output","Package":"example/day01","Test":"TestPart1","Output":"day01_test.go:9: nope\n"}
+{"Action":"fail","Package":"example/day01","Test":"TestPart1"}
+{"Action":"fail","Package":"example/day01"}
+# example/day02
+day02/day02.go:4:2: undefined: nope
+`
+	var got []Event
+	err := Read(strings.NewReader(stream), func(event Event) {
+		got = append(got, event)
+	})
+	is.NoErr(err)
+	is.Equal(got, []Event{
+		{Action: "run", Package: "example/day01", Test: "TestPart1"},
+		{Action: "output", Package: "example/day01", Test: "TestPart1", Output: "day01_test.go:9: nope\n"},
+		{Action: "fail", Package: "example/day01", Test: "TestPart1"},
+	})
+}
@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+)
+
+const (
+	YEAR  = 2020 // Default event year
+	FIRST = 2015 // The first year there was an event
+)
+
+// ResolveYear picks the event year from the --year flag, then $AOC_YEAR, then YEAR,
+// a flagYear of 0 means the flag wasn't given
+func ResolveYear(flagYear int) (int, error) {
+	year := flagYear
+	if year == 0 {
+		year = YEAR
+		if raw := os.Getenv("AOC_YEAR"); raw != "" {
+			var err error
+			year, err = strconv.Atoi(raw)
+			if err != nil {
+				return 0, fmt.Errorf("bad $AOC_YEAR %q: %w", raw, err)
+			}
+		}
+	}
+	if year < FIRST {
+		return 0, fmt.Errorf("year should be %d or later, got: %d", FIRST, year)
+	}
+	return year, nil
+}
+
+// ParseDay parses a command line argument as a day of the advent calendar
+func ParseDay(arg string) (int, error) {
+	day, err := strconv.Atoi(arg)
+	if err != nil {
+		return 0, fmt.Errorf("day should be a valid integer, got: %s", arg)
+	}
+	if day < 1 || day > 25 {
+		return 0, fmt.Errorf("day should be between 1 and 25, got: %d", day)
+	}
+	return day, nil
+}
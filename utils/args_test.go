@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/matryer/is"
+)
+
+func TestResolveYear(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		flag    int
+		want    int
+		wantErr bool
+	}{
+		{name: "default", want: YEAR},
+		{name: "env", env: "2021", want: 2021},
+		{name: "flag beats env", env: "2021", flag: 2022, want: 2022},
+		{name: "bad env", env: "twenty", wantErr: true},
+		{name: "before the first event", flag: 2014, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			is := is.New(t)
+			t.Setenv("AOC_YEAR", tt.env)
+
+			got, err := ResolveYear(tt.flag)
+			if tt.wantErr {
+				is.True(err != nil)
+				return
+			}
+			is.NoErr(err)
+			is.Equal(got, tt.want)
+		})
+	}
+}
+
+func TestParseDay(t *testing.T) {
+	is := is.New(t)
+
+	day, err := ParseDay("7")
+	is.NoErr(err)
+	is.Equal(day, 7)
+
+	for _, arg := range []string{"0", "26", "seven", ""} {
+		_, err := ParseDay(arg)
+		is.True(err != nil) // Bad day accepted
+	}
+}
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"fmt"
 	"path/filepath"
 	"runtime"
+	"strconv"
 )
 
 // Root returns the absolute path to the project root directory
@@ -14,3 +16,9 @@ func Root() string {
 	}
 	return filepath.Join(file, "../..")
 }
+
+// Dir returns the absolute path to the directory for a day's puzzle,
+// days live under their year e.g. <root>/2020/day01
+func Dir(year, day int) string {
+	return filepath.Join(Root(), strconv.Itoa(year), fmt.Sprintf("day%02d", day))
+}